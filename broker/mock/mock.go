@@ -72,3 +72,7 @@ func (b *Broker) Publish(ctx context.Context, channel string, payload any) error
 func (b *Broker) Shutdown(ctx context.Context) {
 	zerolog.Ctx(ctx).Debug().Msg("Mock broker shutdown")
 }
+
+func (b *Broker) Ping(_ context.Context) error {
+	return nil
+}
@@ -20,7 +20,7 @@ func init() {
 }
 
 type Broker struct {
-	cl     *redis.Client
+	cl     redis.UniversalClient
 	prefix string
 }
 
@@ -31,15 +31,21 @@ func newRedis(ctx context.Context, connst string) (broker.Broker, error) {
 		return nil, errorx.Wrap(err, "parse connection url")
 	}
 
-	cl, err := gredis.New(ctx, connst)
+	cl, err := gredis.NewShared(ctx, connst)
 	if err != nil {
 		return nil, errorx.Wrap(err, "create redis client")
 	}
 
+	return New(cl, u.Fragment), nil
+}
+
+// New creates a broker over an existing client, channels are prefixed with prefix.
+// Shutdown closes the client.
+func New(cl redis.UniversalClient, prefix string) *Broker {
 	return &Broker{
 		cl:     cl,
-		prefix: u.Fragment,
-	}, nil
+		prefix: prefix,
+	}
 }
 
 func (b *Broker) Subscribe(ctx context.Context, mh broker.MessageHandler, channels ...string) {
@@ -78,6 +84,10 @@ func (b *Broker) Shutdown(ctx context.Context) {
 	}
 }
 
+func (b *Broker) Ping(ctx context.Context) error {
+	return errorx.Wrap(b.cl.Ping(ctx).Err(), "ping redis")
+}
+
 func (b *Broker) subscriptionHandler(ctx context.Context, mh broker.MessageHandler, ch <-chan *redis.Message) {
 	logger := zerolog.Ctx(ctx)
 
@@ -4,11 +4,83 @@ import (
 	"context"
 	"errors"
 	"time"
+
+	"github.com/sovamorco/errorx"
 )
 
-var ErrNotExist = errors.New("key does not exist")
+var (
+	ErrNotExist    = errors.New("key does not exist")
+	ErrUnsupported = errors.New("operation is not supported by cache")
+)
 
 type Cache interface {
 	Set(ctx context.Context, key string, value []byte, lifetime time.Duration) error
 	Get(ctx context.Context, key string) ([]byte, error)
+
+	// SetWithTags sets the value and attaches it to the tags, so it can be removed with InvalidateTags.
+	SetWithTags(ctx context.Context, key string, value []byte, lifetime time.Duration, tags ...string) error
+	// InvalidateTags removes every key attached to any of the tags.
+	InvalidateTags(ctx context.Context, tags ...string) error
+
+	// IncrBy atomically adds delta to the integer stored at key and returns the new value.
+	// missing keys start from 0 and are created with lifetime, existing keys keep their lifetime.
+	IncrBy(ctx context.Context, key string, delta int64, lifetime time.Duration) (int64, error)
+
+	// SetIfNotExists sets the value only if key does not exist and reports whether it was set.
+	SetIfNotExists(ctx context.Context, key string, value []byte, lifetime time.Duration) (bool, error)
+	// GetVersioned returns the value along with its version.
+	// versions are opaque, provider-specific and change whenever the value changes.
+	GetVersioned(ctx context.Context, key string) ([]byte, string, error)
+	// CompareAndSwap sets the value only if its version is still oldVersion and reports whether it was set.
+	CompareAndSwap(ctx context.Context, key, oldVersion string, value []byte, lifetime time.Duration) (bool, error)
+}
+
+// TokenBucket is implemented by caches that can atomically refill and take tokens from a bucket.
+type TokenBucket interface {
+	// TakeTokens refills the bucket at rate tokens per second up to burst and takes n tokens if there are enough.
+	TakeTokens(ctx context.Context, key string, rate float64, burst, n int64) (Tokens, error)
+}
+
+// TTLGetter is implemented by caches that can return the remaining lifetime of values.
+type TTLGetter interface {
+	// GetWithTTL returns the value along with its remaining lifetime, zero if it does not expire.
+	GetWithTTL(ctx context.Context, key string) ([]byte, time.Duration, error)
+}
+
+type Tokens struct {
+	Taken     bool
+	Remaining int64
+	// time until n tokens are available, zero if they were taken.
+	RetryAfter time.Duration
+}
+
+func Incr(ctx context.Context, c Cache, key string, lifetime time.Duration) (int64, error) {
+	return c.IncrBy(ctx, key, 1, lifetime)
+}
+
+func Decr(ctx context.Context, c Cache, key string, lifetime time.Duration) (int64, error) {
+	return c.IncrBy(ctx, key, -1, lifetime)
+}
+
+// TakeTokens calls TakeTokens of c, if it implements TokenBucket.
+func TakeTokens(ctx context.Context, c Cache, key string, rate float64, burst, n int64) (Tokens, error) {
+	tb, ok := c.(TokenBucket)
+	if !ok {
+		return Tokens{}, errorx.Wrap(ErrUnsupported, "take tokens")
+	}
+
+	return tb.TakeTokens(ctx, key, rate, burst, n)
+}
+
+// GetWithTTL calls GetWithTTL of c, if it implements TTLGetter.
+// otherwise the value is returned with zero lifetime, same as for values that do not expire.
+func GetWithTTL(ctx context.Context, c Cache, key string) ([]byte, time.Duration, error) {
+	tg, ok := c.(TTLGetter)
+	if !ok {
+		v, err := c.Get(ctx, key)
+
+		return v, 0, err
+	}
+
+	return tg.GetWithTTL(ctx, key)
 }
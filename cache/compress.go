@@ -0,0 +1,128 @@
+package cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/sovamorco/errorx"
+)
+
+type Compression string
+
+const (
+	Gzip Compression = "gzip"
+	Zstd Compression = "zstd"
+)
+
+const (
+	compressionNone byte = iota
+	compressionGzip
+	compressionZstd
+)
+
+// every value written by Compressor starts with it followed by the compression byte.
+const compressionMagic = "\x00cmp"
+
+type CompressionConfig struct {
+	Algorithm Compression `mapstructure:"algorithm"`
+	// values shorter than threshold are stored uncompressed.
+	Threshold int `mapstructure:"threshold"`
+}
+
+// Compressor compresses values longer than threshold.
+type Compressor struct {
+	algorithm Compression
+	threshold int
+	zenc      *zstd.Encoder
+	zdec      *zstd.Decoder
+}
+
+func NewCompressor(cfg CompressionConfig) (*Compressor, error) {
+	c := &Compressor{
+		algorithm: cfg.Algorithm,
+		threshold: cfg.Threshold,
+		zenc:      nil,
+		zdec:      nil,
+	}
+
+	var err error
+
+	switch cfg.Algorithm {
+	case Gzip:
+	case Zstd:
+		c.zenc, err = zstd.NewWriter(nil)
+		if err != nil {
+			return nil, errorx.Wrap(err, "create zstd encoder")
+		}
+	default:
+		return nil, errorx.IllegalArgument.New("unknown compression algorithm: %s", cfg.Algorithm)
+	}
+
+	// zstd decoder is always needed to read values written before switching algorithms.
+	c.zdec, err = zstd.NewReader(nil)
+	if err != nil {
+		return nil, errorx.Wrap(err, "create zstd decoder")
+	}
+
+	return c, nil
+}
+
+func (c *Compressor) Encode(value []byte) ([]byte, error) {
+	res := []byte(compressionMagic)
+
+	if len(value) < c.threshold {
+		return append(append(res, compressionNone), value...), nil
+	}
+
+	switch c.algorithm {
+	case Gzip:
+		buf := bytes.NewBuffer(append(res, compressionGzip))
+		w := gzip.NewWriter(buf)
+
+		_, err := w.Write(value)
+		if err != nil {
+			return nil, errorx.Wrap(err, "write gzip")
+		}
+
+		err = w.Close()
+		if err != nil {
+			return nil, errorx.Wrap(err, "close gzip")
+		}
+
+		return buf.Bytes(), nil
+	case Zstd:
+		return c.zenc.EncodeAll(value, append(res, compressionZstd)), nil
+	}
+
+	return nil, errorx.IllegalState.New("unknown compression algorithm: %s", c.algorithm)
+}
+
+func (c *Compressor) Decode(value []byte) ([]byte, error) {
+	if len(value) <= len(compressionMagic) || string(value[:len(compressionMagic)]) != compressionMagic {
+		return value, nil
+	}
+
+	kind, body := value[len(compressionMagic)], value[len(compressionMagic)+1:]
+
+	switch kind {
+	case compressionNone:
+		return body, nil
+	case compressionGzip:
+		r, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, errorx.Wrap(err, "create gzip reader")
+		}
+
+		res, err := io.ReadAll(r)
+
+		return res, errorx.Wrap(err, "read gzip")
+	case compressionZstd:
+		res, err := c.zdec.DecodeAll(body, nil)
+
+		return res, errorx.Wrap(err, "decode zstd")
+	}
+
+	return nil, errorx.IllegalFormat.New("unknown compression byte %d", kind)
+}
@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"math"
+
+	"github.com/sovamorco/errorx"
+)
+
+// every value written by Encryptor starts with it,
+// followed by key id length, key id, nonce and sealed value.
+const encryptionMagic = "\x00enc"
+
+type EncryptionConfig struct {
+	// id of the key used to encrypt new values.
+	KeyID string `mapstructure:"key_id"`
+	// AES keys by id, old keys have to be kept while values encrypted with them may still be cached.
+	Keys map[string][]byte `mapstructure:"keys"`
+}
+
+// Encryptor encrypts values with AES-GCM, embedding the key id so that keys can be rotated.
+type Encryptor struct {
+	keyID string
+	aeads map[string]cipher.AEAD
+}
+
+func NewEncryptor(cfg EncryptionConfig) (*Encryptor, error) {
+	if _, ok := cfg.Keys[cfg.KeyID]; !ok {
+		return nil, errorx.IllegalArgument.New("no encryption key with id %q", cfg.KeyID)
+	}
+
+	e := &Encryptor{
+		keyID: cfg.KeyID,
+		aeads: make(map[string]cipher.AEAD, len(cfg.Keys)),
+	}
+
+	for id, key := range cfg.Keys {
+		if len(id) > math.MaxUint8 {
+			return nil, errorx.IllegalArgument.New("encryption key id %q is too long", id)
+		}
+
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, errorx.Wrap(err, "create cipher for key %q", id)
+		}
+
+		e.aeads[id], err = cipher.NewGCM(block)
+		if err != nil {
+			return nil, errorx.Wrap(err, "create gcm for key %q", id)
+		}
+	}
+
+	return e, nil
+}
+
+func (e *Encryptor) Encode(value []byte) ([]byte, error) {
+	aead := e.aeads[e.keyID]
+
+	res := make([]byte, 0, len(encryptionMagic)+1+len(e.keyID)+aead.NonceSize()+len(value)+aead.Overhead())
+	res = append(res, encryptionMagic...)
+	res = append(res, byte(len(e.keyID)))
+	res = append(res, e.keyID...)
+
+	nonce := make([]byte, aead.NonceSize())
+
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return nil, errorx.Wrap(err, "generate nonce")
+	}
+
+	res = append(res, nonce...)
+
+	return aead.Seal(res, nonce, value, []byte(e.keyID)), nil
+}
+
+// Decode returns values without the encryption header as is, they are assumed to be written in plain text
+// before encryption was enabled.
+func (e *Encryptor) Decode(value []byte) ([]byte, error) {
+	if len(value) <= len(encryptionMagic) || string(value[:len(encryptionMagic)]) != encryptionMagic {
+		return value, nil
+	}
+
+	rest := value[len(encryptionMagic):]
+
+	idLen := int(rest[0])
+	if len(rest) < 1+idLen {
+		return nil, errorx.IllegalFormat.New("encrypted value is too short")
+	}
+
+	keyID := string(rest[1 : 1+idLen])
+	rest = rest[1+idLen:]
+
+	aead, ok := e.aeads[keyID]
+	if !ok {
+		return nil, errorx.IllegalState.New("no encryption key with id %q", keyID)
+	}
+
+	if len(rest) < aead.NonceSize() {
+		return nil, errorx.IllegalFormat.New("encrypted value is too short")
+	}
+
+	res, err := aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], []byte(keyID))
+
+	return res, errorx.Wrap(err, "decrypt value")
+}
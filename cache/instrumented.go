@@ -0,0 +1,174 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sovamorco/gommon/metrics"
+)
+
+const (
+	MetricHits     = "cache_hits_total"
+	MetricMisses   = "cache_misses_total"
+	MetricErrors   = "cache_errors_total"
+	MetricSets     = "cache_sets_total"
+	MetricDuration = "cache_operation_duration_seconds"
+)
+
+// Instrumented records hits, misses, errors, sets and latency of every operation of the underlying cache.
+// all metrics are labeled with namespace, errors and latency additionally with operation.
+type Instrumented struct {
+	c         Cache
+	m         metrics.Metrics
+	namespace string
+}
+
+func Instrument(c Cache, m metrics.Metrics, namespace string) *Instrumented {
+	return &Instrumented{
+		c:         c,
+		m:         m,
+		namespace: namespace,
+	}
+}
+
+func (i *Instrumented) Set(ctx context.Context, key string, value []byte, lifetime time.Duration) error {
+	defer i.observe("set", time.Now())
+
+	err := i.c.Set(ctx, key, value, lifetime)
+	i.recordSet("set", err)
+
+	return err
+}
+
+func (i *Instrumented) Get(ctx context.Context, key string) ([]byte, error) {
+	defer i.observe("get", time.Now())
+
+	v, err := i.c.Get(ctx, key)
+	i.recordGet("get", err)
+
+	return v, err
+}
+
+func (i *Instrumented) GetWithTTL(ctx context.Context, key string) ([]byte, time.Duration, error) {
+	defer i.observe("get_with_ttl", time.Now())
+
+	v, ttl, err := GetWithTTL(ctx, i.c, key)
+	i.recordGet("get_with_ttl", err)
+
+	return v, ttl, err
+}
+
+func (i *Instrumented) SetWithTags(ctx context.Context, key string, value []byte, lifetime time.Duration,
+	tags ...string,
+) error {
+	defer i.observe("set_with_tags", time.Now())
+
+	err := i.c.SetWithTags(ctx, key, value, lifetime, tags...)
+	i.recordSet("set_with_tags", err)
+
+	return err
+}
+
+func (i *Instrumented) InvalidateTags(ctx context.Context, tags ...string) error {
+	defer i.observe("invalidate_tags", time.Now())
+
+	err := i.c.InvalidateTags(ctx, tags...)
+	i.recordError("invalidate_tags", err)
+
+	return err
+}
+
+func (i *Instrumented) IncrBy(ctx context.Context, key string, delta int64, lifetime time.Duration) (int64, error) {
+	defer i.observe("incr_by", time.Now())
+
+	res, err := i.c.IncrBy(ctx, key, delta, lifetime)
+	i.recordError("incr_by", err)
+
+	return res, err
+}
+
+func (i *Instrumented) SetIfNotExists(ctx context.Context, key string, value []byte,
+	lifetime time.Duration,
+) (bool, error) {
+	defer i.observe("set_if_not_exists", time.Now())
+
+	set, err := i.c.SetIfNotExists(ctx, key, value, lifetime)
+	if set || err != nil {
+		i.recordSet("set_if_not_exists", err)
+	}
+
+	return set, err
+}
+
+func (i *Instrumented) GetVersioned(ctx context.Context, key string) ([]byte, string, error) {
+	defer i.observe("get_versioned", time.Now())
+
+	v, version, err := i.c.GetVersioned(ctx, key)
+	i.recordGet("get_versioned", err)
+
+	return v, version, err
+}
+
+func (i *Instrumented) CompareAndSwap(ctx context.Context, key, oldVersion string, value []byte,
+	lifetime time.Duration,
+) (bool, error) {
+	defer i.observe("compare_and_swap", time.Now())
+
+	swapped, err := i.c.CompareAndSwap(ctx, key, oldVersion, value, lifetime)
+	if swapped || err != nil {
+		i.recordSet("compare_and_swap", err)
+	}
+
+	return swapped, err
+}
+
+func (i *Instrumented) TakeTokens(ctx context.Context, key string, rate float64, burst, n int64) (Tokens, error) {
+	defer i.observe("take_tokens", time.Now())
+
+	res, err := TakeTokens(ctx, i.c, key, rate, burst, n)
+	i.recordError("take_tokens", err)
+
+	return res, err
+}
+
+func (i *Instrumented) observe(op string, start time.Time) {
+	i.m.Observe(MetricDuration, i.opLabels(op), time.Since(start).Seconds())
+}
+
+func (i *Instrumented) recordGet(op string, err error) {
+	switch {
+	case err == nil:
+		i.m.Inc(MetricHits, i.labels())
+	case errors.Is(err, ErrNotExist):
+		i.m.Inc(MetricMisses, i.labels())
+	default:
+		i.recordError(op, err)
+	}
+}
+
+func (i *Instrumented) recordSet(op string, err error) {
+	if err != nil {
+		i.recordError(op, err)
+
+		return
+	}
+
+	i.m.Inc(MetricSets, i.labels())
+}
+
+func (i *Instrumented) recordError(op string, err error) {
+	if err == nil || errors.Is(err, ErrNotExist) {
+		return
+	}
+
+	i.m.Inc(MetricErrors, i.opLabels(op))
+}
+
+func (i *Instrumented) labels() metrics.Labels {
+	return metrics.Labels{"namespace": i.namespace}
+}
+
+func (i *Instrumented) opLabels(op string) metrics.Labels {
+	return metrics.Labels{"namespace": i.namespace, "operation": op}
+}
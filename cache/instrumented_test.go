@@ -0,0 +1,44 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sovamorco/gommon/cache"
+	"github.com/sovamorco/gommon/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstrumented(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	m := metrics.NewMemory(nil)
+	c := cache.Instrument(newMockCache(t), m, "test")
+
+	_, err := c.Get(ctx, "key")
+	require.ErrorIs(t, err, cache.ErrNotExist)
+
+	require.NoError(t, c.Set(ctx, "key", []byte("value"), time.Minute))
+
+	_, err = c.Get(ctx, "key")
+	require.NoError(t, err)
+
+	_, err = c.IncrBy(ctx, "key", 1, time.Minute)
+	require.Error(t, err)
+
+	labels := metrics.Labels{"namespace": "test"}
+
+	assert.InDelta(t, 1, m.Counter(cache.MetricHits, labels), 0)
+	assert.InDelta(t, 1, m.Counter(cache.MetricMisses, labels), 0)
+	assert.InDelta(t, 1, m.Counter(cache.MetricSets, labels), 0)
+	assert.InDelta(t, 1, m.Counter(cache.MetricErrors, metrics.Labels{"namespace": "test", "operation": "incr_by"}), 0)
+	assert.Zero(t, m.Counter(cache.MetricErrors, metrics.Labels{"namespace": "test", "operation": "get"}))
+
+	assert.Equal(t, uint64(2), m.Histogram(cache.MetricDuration,
+		metrics.Labels{"namespace": "test", "operation": "get"}).Count)
+	assert.Equal(t, uint64(1), m.Histogram(cache.MetricDuration,
+		metrics.Labels{"namespace": "test", "operation": "set"}).Count)
+}
@@ -0,0 +1,101 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// local is a bounded in-memory LRU with per-entry expiry.
+type local struct {
+	size    int
+	entries map[string]*list.Element
+	order   *list.List
+	mu      sync.Mutex `exhaustruct:"optional"`
+}
+
+type localEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+func newLocal(size int) *local {
+	return &local{
+		size:    size,
+		entries: make(map[string]*list.Element, size),
+		order:   list.New(),
+	}
+}
+
+func (l *local) get(key string) ([]byte, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	el, ok := l.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	//nolint:forcetypeassert // only localEntry is stored.
+	e := el.Value.(*localEntry)
+	if !time.Now().Before(e.expiresAt) {
+		l.removeElement(el)
+
+		return nil, false
+	}
+
+	l.order.MoveToFront(el)
+
+	return e.value, true
+}
+
+func (l *local) set(key string, value []byte, lifetime time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e := &localEntry{
+		key:       key,
+		value:     value,
+		expiresAt: time.Now().Add(lifetime),
+	}
+
+	if el, ok := l.entries[key]; ok {
+		el.Value = e
+		l.order.MoveToFront(el)
+
+		return
+	}
+
+	l.entries[key] = l.order.PushFront(e)
+
+	for l.order.Len() > l.size {
+		l.removeElement(l.order.Back())
+	}
+}
+
+func (l *local) delete(keys ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, key := range keys {
+		if el, ok := l.entries[key]; ok {
+			l.removeElement(el)
+		}
+	}
+}
+
+func (l *local) purge() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	clear(l.entries)
+	l.order.Init()
+}
+
+func (l *local) removeElement(el *list.Element) {
+	l.order.Remove(el)
+
+	//nolint:forcetypeassert // only localEntry is stored.
+	delete(l.entries, el.Value.(*localEntry).key)
+}
@@ -2,9 +2,13 @@ package mock
 
 import (
 	"context"
+	"math"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/sovamorco/errorx"
 	"github.com/sovamorco/gommon/cache"
 )
 
@@ -16,52 +20,243 @@ func init() {
 type cacheValue struct {
 	versionKey string
 	content    []byte
+	// zero value means the entry never expires, same as redis.
+	expiresAt time.Time
+}
+
+func (v cacheValue) expired(now time.Time) bool {
+	return !v.expiresAt.IsZero() && !now.Before(v.expiresAt)
+}
+
+type bucket struct {
+	tokens float64
+	ts     time.Time
 }
 
 type Cache struct {
 	m map[string]cacheValue
+	// keys attached to each tag, kept until the tag is invalidated like in redis.
+	tags    map[string]map[string]struct{}
+	buckets map[string]bucket
+	mu      sync.Mutex `exhaustruct:"optional"`
 }
 
 //nolint:ireturn // required by cache.Register.
 func newMock(_ context.Context, _ string) (cache.Cache, error) {
 	return &Cache{
-		m: make(map[string]cacheValue),
+		m:       make(map[string]cacheValue),
+		tags:    make(map[string]map[string]struct{}),
+		buckets: make(map[string]bucket),
 	}, nil
 }
 
-func (c *Cache) Set(ctx context.Context, key string, value []byte, lifetime time.Duration) error {
-	vk := uuid.New().String()
+func (c *Cache) Set(_ context.Context, key string, value []byte, lifetime time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.m[key] = newValue(value, lifetime)
+
+	return nil
+}
+
+func (c *Cache) Get(_ context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	c.m[key] = cacheValue{
-		versionKey: vk,
-		content:    value,
+	v, ok := c.load(key)
+	if !ok {
+		return nil, cache.ErrNotExist
 	}
 
-	go func() {
-		select {
-		case <-ctx.Done():
-			return
-		case <-time.After(lifetime):
-		}
+	return v.content, nil
+}
+
+func (c *Cache) GetWithTTL(_ context.Context, key string) ([]byte, time.Duration, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	v, ok := c.load(key)
+	if !ok {
+		return nil, 0, cache.ErrNotExist
+	}
+
+	if v.expiresAt.IsZero() {
+		return v.content, 0, nil
+	}
+
+	return v.content, time.Until(v.expiresAt), nil
+}
 
-		v, ok := c.m[key]
-		if !ok {
-			return
+func (c *Cache) SetWithTags(_ context.Context, key string, value []byte, lifetime time.Duration,
+	tags ...string,
+) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.m[key] = newValue(value, lifetime)
+
+	for _, tag := range tags {
+		if _, ok := c.tags[tag]; !ok {
+			c.tags[tag] = make(map[string]struct{})
 		}
 
-		if v.versionKey == vk {
+		c.tags[tag][key] = struct{}{}
+	}
+
+	return nil
+}
+
+func (c *Cache) InvalidateTags(_ context.Context, tags ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, tag := range tags {
+		for key := range c.tags[tag] {
 			delete(c.m, key)
 		}
-	}()
+
+		delete(c.tags, tag)
+	}
 
 	return nil
 }
 
-func (c *Cache) Get(_ context.Context, key string) ([]byte, error) {
+func (c *Cache) IncrBy(_ context.Context, key string, delta int64, lifetime time.Duration) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	v, ok := c.load(key)
+	if !ok {
+		c.m[key] = newValue([]byte(strconv.FormatInt(delta, 10)), lifetime)
+
+		return delta, nil
+	}
+
+	cur, err := strconv.ParseInt(string(v.content), 10, 64)
+	if err != nil {
+		return 0, errorx.IllegalState.Wrap(err, "value is not an integer")
+	}
+
+	cur += delta
+
+	nv := newValue([]byte(strconv.FormatInt(cur, 10)), 0)
+	nv.expiresAt = v.expiresAt
+	c.m[key] = nv
+
+	return cur, nil
+}
+
+func (c *Cache) TakeTokens(_ context.Context, key string, rate float64, burst, n int64) (cache.Tokens, error) {
+	if rate <= 0 || burst <= 0 {
+		return cache.Tokens{}, errorx.IllegalArgument.New("rate and burst must be positive, got %v and %d", rate, burst)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+
+	b, ok := c.buckets[key]
+	if !ok {
+		b = bucket{
+			tokens: float64(burst),
+			ts:     now,
+		}
+	}
+
+	b.tokens = math.Min(float64(burst), b.tokens+now.Sub(b.ts).Seconds()*rate)
+	b.ts = now
+
+	res := cache.Tokens{
+		Taken:      false,
+		Remaining:  0,
+		RetryAfter: 0,
+	}
+
+	if b.tokens >= float64(n) {
+		b.tokens -= float64(n)
+		res.Taken = true
+	} else {
+		res.RetryAfter = time.Duration((float64(n) - b.tokens) / rate * float64(time.Second))
+	}
+
+	res.Remaining = int64(b.tokens)
+	c.buckets[key] = b
+
+	return res, nil
+}
+
+func (c *Cache) SetIfNotExists(_ context.Context, key string, value []byte, lifetime time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.load(key); ok {
+		return false, nil
+	}
+
+	c.m[key] = newValue(value, lifetime)
+
+	return true, nil
+}
+
+func (c *Cache) GetVersioned(_ context.Context, key string) ([]byte, string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	v, ok := c.load(key)
+	if !ok {
+		return nil, "", cache.ErrNotExist
+	}
+
+	return v.content, v.versionKey, nil
+}
+
+func (c *Cache) CompareAndSwap(_ context.Context, key, oldVersion string, value []byte,
+	lifetime time.Duration,
+) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	v, ok := c.load(key)
+	if !ok {
+		return false, cache.ErrNotExist
+	}
+
+	if v.versionKey != oldVersion {
+		return false, nil
+	}
+
+	c.m[key] = newValue(value, lifetime)
+
+	return true, nil
+}
+
+// load returns the entry for key, evicting it if it has expired.
+// c.mu has to be held by the caller.
+func (c *Cache) load(key string) (cacheValue, bool) {
 	v, ok := c.m[key]
 	if !ok {
-		return nil, cache.ErrNotExist
+		return cacheValue{}, false
 	}
 
-	return v.content, nil
+	if v.expired(time.Now()) {
+		delete(c.m, key)
+
+		return cacheValue{}, false
+	}
+
+	return v, true
+}
+
+func newValue(content []byte, lifetime time.Duration) cacheValue {
+	var expiresAt time.Time
+	if lifetime > 0 {
+		expiresAt = time.Now().Add(lifetime)
+	}
+
+	return cacheValue{
+		versionKey: uuid.New().String(),
+		content:    content,
+		expiresAt:  expiresAt,
+	}
 }
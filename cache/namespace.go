@@ -0,0 +1,189 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/sovamorco/errorx"
+)
+
+// MaxKeyLength is the length above which namespaced keys are replaced with their hash.
+const MaxKeyLength = 250
+
+// Namespaced rewrites keys to "<name>:<version>:<generation>:<key>".
+// changing version rolls the cache format on deploy, bumping generation with Invalidate
+// drops every key of the namespace without having to enumerate them.
+// the generation is read from the underlying cache, so every operation costs an extra GET.
+type Namespaced struct {
+	c         Cache
+	prefix    string
+	genKey    string
+	tagPrefix string
+}
+
+func Namespace(c Cache, name, version string) *Namespaced {
+	prefix := name + ":" + version
+
+	return &Namespaced{
+		c:         c,
+		prefix:    prefix,
+		genKey:    "__ns:" + prefix + ":generation",
+		tagPrefix: prefix + ":",
+	}
+}
+
+func (n *Namespaced) Set(ctx context.Context, key string, value []byte, lifetime time.Duration) error {
+	key, err := n.key(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	return errorx.Wrap(n.c.Set(ctx, key, value, lifetime), "set namespaced value")
+}
+
+func (n *Namespaced) Get(ctx context.Context, key string) ([]byte, error) {
+	key, err := n.key(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := n.c.Get(ctx, key)
+
+	return v, errorx.Wrap(err, "get namespaced value")
+}
+
+func (n *Namespaced) GetWithTTL(ctx context.Context, key string) ([]byte, time.Duration, error) {
+	key, err := n.key(ctx, key)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	v, ttl, err := GetWithTTL(ctx, n.c, key)
+
+	return v, ttl, errorx.Wrap(err, "get namespaced value")
+}
+
+func (n *Namespaced) SetWithTags(ctx context.Context, key string, value []byte, lifetime time.Duration,
+	tags ...string,
+) error {
+	key, err := n.key(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	err = n.c.SetWithTags(ctx, key, value, lifetime, n.tags(tags)...)
+
+	return errorx.Wrap(err, "set namespaced value")
+}
+
+func (n *Namespaced) InvalidateTags(ctx context.Context, tags ...string) error {
+	return errorx.Wrap(n.c.InvalidateTags(ctx, n.tags(tags)...), "invalidate namespaced tags")
+}
+
+func (n *Namespaced) IncrBy(ctx context.Context, key string, delta int64, lifetime time.Duration) (int64, error) {
+	key, err := n.key(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := n.c.IncrBy(ctx, key, delta, lifetime)
+
+	return res, errorx.Wrap(err, "increment namespaced value")
+}
+
+func (n *Namespaced) SetIfNotExists(ctx context.Context, key string, value []byte,
+	lifetime time.Duration,
+) (bool, error) {
+	key, err := n.key(ctx, key)
+	if err != nil {
+		return false, err
+	}
+
+	set, err := n.c.SetIfNotExists(ctx, key, value, lifetime)
+
+	return set, errorx.Wrap(err, "set namespaced value if not exists")
+}
+
+func (n *Namespaced) GetVersioned(ctx context.Context, key string) ([]byte, string, error) {
+	key, err := n.key(ctx, key)
+	if err != nil {
+		return nil, "", err
+	}
+
+	v, version, err := n.c.GetVersioned(ctx, key)
+
+	return v, version, errorx.Wrap(err, "get namespaced value")
+}
+
+func (n *Namespaced) CompareAndSwap(ctx context.Context, key, oldVersion string, value []byte,
+	lifetime time.Duration,
+) (bool, error) {
+	key, err := n.key(ctx, key)
+	if err != nil {
+		return false, err
+	}
+
+	swapped, err := n.c.CompareAndSwap(ctx, key, oldVersion, value, lifetime)
+
+	return swapped, errorx.Wrap(err, "compare and swap namespaced value")
+}
+
+func (n *Namespaced) TakeTokens(ctx context.Context, key string, rate float64, burst, count int64) (Tokens, error) {
+	key, err := n.key(ctx, key)
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	return TakeTokens(ctx, n.c, key, rate, burst, count)
+}
+
+// Invalidate drops every key of the namespace by moving it to the next generation.
+// keys of previous generations are left to expire.
+func (n *Namespaced) Invalidate(ctx context.Context) error {
+	_, err := n.c.IncrBy(ctx, n.genKey, 1, 0)
+
+	return errorx.Wrap(err, "bump namespace generation")
+}
+
+func (n *Namespaced) key(ctx context.Context, key string) (string, error) {
+	gen, err := n.generation(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	prefix := n.prefix + ":" + strconv.FormatInt(gen, 10) + ":"
+
+	if len(prefix)+len(key) <= MaxKeyLength {
+		return prefix + key, nil
+	}
+
+	sum := sha256.Sum256([]byte(key))
+
+	return prefix + "#" + hex.EncodeToString(sum[:]), nil
+}
+
+func (n *Namespaced) generation(ctx context.Context) (int64, error) {
+	v, err := n.c.Get(ctx, n.genKey)
+	if errors.Is(err, ErrNotExist) {
+		return 0, nil
+	} else if err != nil {
+		return 0, errorx.Wrap(err, "get namespace generation")
+	}
+
+	gen, err := strconv.ParseInt(string(v), 10, 64)
+
+	return gen, errorx.Wrap(err, "parse namespace generation")
+}
+
+func (n *Namespaced) tags(tags []string) []string {
+	res := make([]string, len(tags))
+	for i, tag := range tags {
+		res[i] = n.tagPrefix + tag
+	}
+
+	return res
+}
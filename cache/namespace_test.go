@@ -0,0 +1,89 @@
+package cache_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sovamorco/gommon/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNamespaceKeys(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c := newMockCache(t)
+	ns := cache.Namespace(c, "users", "v1")
+
+	require.NoError(t, ns.Set(ctx, "key", []byte("value"), time.Minute))
+
+	res, err := c.Get(ctx, "users:v1:0:key")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value"), res)
+
+	long := strings.Repeat("k", cache.MaxKeyLength)
+
+	require.NoError(t, ns.Set(ctx, long, []byte("long"), time.Minute))
+
+	_, err = c.Get(ctx, "users:v1:0:"+long)
+	require.ErrorIs(t, err, cache.ErrNotExist)
+
+	res, err = ns.Get(ctx, long)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("long"), res)
+
+	other := cache.Namespace(c, "users", "v2")
+
+	_, err = other.Get(ctx, "key")
+	require.ErrorIs(t, err, cache.ErrNotExist)
+}
+
+func TestNamespaceInvalidate(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c := newMockCache(t)
+	ns := cache.Namespace(c, "users", "v1")
+	other := cache.Namespace(c, "posts", "v1")
+
+	require.NoError(t, ns.Set(ctx, "key", []byte("value"), time.Minute))
+	require.NoError(t, other.Set(ctx, "key", []byte("value"), time.Minute))
+
+	require.NoError(t, ns.Invalidate(ctx))
+
+	_, err := ns.Get(ctx, "key")
+	require.ErrorIs(t, err, cache.ErrNotExist)
+
+	_, err = other.Get(ctx, "key")
+	require.NoError(t, err)
+
+	require.NoError(t, ns.Set(ctx, "key", []byte("new"), time.Minute))
+
+	res, err := c.Get(ctx, "users:v1:1:key")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("new"), res)
+}
+
+func TestNamespaceTags(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c := newMockCache(t)
+	ns := cache.Namespace(c, "users", "v1")
+
+	require.NoError(t, ns.SetWithTags(ctx, "key", []byte("value"), time.Minute, "tag"))
+	require.NoError(t, c.SetWithTags(ctx, "plain", []byte("value"), time.Minute, "tag"))
+
+	require.NoError(t, c.InvalidateTags(ctx, "tag"))
+
+	_, err := ns.Get(ctx, "key")
+	require.NoError(t, err)
+
+	require.NoError(t, ns.InvalidateTags(ctx, "tag"))
+
+	_, err = ns.Get(ctx, "key")
+	require.ErrorIs(t, err, cache.ErrNotExist)
+}
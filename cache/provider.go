@@ -4,11 +4,22 @@ import (
 	"context"
 	"fmt"
 	"sync"
+
+	"github.com/sovamorco/errorx"
 )
 
 type Config struct {
 	Provider string `mapstructure:"provider"`
 	URL      string `mapstructure:"url"`
+
+	// optional transformers, values are compressed before they are encrypted.
+	Compression *CompressionConfig `mapstructure:"compression"`
+	Encryption  *EncryptionConfig  `mapstructure:"encryption"`
+
+	// optional local tier in front of the provider, see Tiered.
+	// the tiered cache owns the invalidation broker, which is shut down by closing the cache with io.Closer.
+	// invalidations stop being received once ctx passed to New is done.
+	Local *LocalConfig `mapstructure:"local"`
 }
 
 type builder func(ctx context.Context, connst string) (Cache, error)
@@ -52,5 +63,42 @@ func New(ctx context.Context, cfg Config) (Cache, error) {
 		}
 	}
 
-	return pf(ctx, cfg.URL)
+	c, err := pf(ctx, cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	return wrap(ctx, c, cfg)
+}
+
+//nolint:ireturn // depends on config.
+func wrap(ctx context.Context, c Cache, cfg Config) (Cache, error) {
+	if cfg.Encryption != nil {
+		e, err := NewEncryptor(*cfg.Encryption)
+		if err != nil {
+			return nil, errorx.Wrap(err, "create encryptor")
+		}
+
+		c = Transform(c, e)
+	}
+
+	if cfg.Compression != nil {
+		cmp, err := NewCompressor(*cfg.Compression)
+		if err != nil {
+			return nil, errorx.Wrap(err, "create compressor")
+		}
+
+		c = Transform(c, cmp)
+	}
+
+	if cfg.Local != nil {
+		t, err := newTieredFromConfig(ctx, c, *cfg.Local)
+		if err != nil {
+			return nil, errorx.Wrap(err, "create tiered cache")
+		}
+
+		c = t
+	}
+
+	return c, nil
 }
@@ -12,13 +12,171 @@ import (
 	"github.com/sovamorco/gommon/gredis"
 )
 
+// adds the key to the tag set and extends its expiry to cover the key.
+// a set that already has no expiry holds keys without expiry and is left as is.
+//
+//nolint:gochecknoglobals // scripts are compiled once.
+var tagScript = redis.NewScript(`
+local isNew = redis.call('EXISTS', KEYS[1]) == 0
+redis.call('SADD', KEYS[1], ARGV[1])
+
+local ttl = tonumber(ARGV[2])
+if ttl <= 0 then
+	redis.call('PERSIST', KEYS[1])
+	return 0
+end
+
+local current = redis.call('PTTL', KEYS[1])
+if isNew or (current >= 0 and current < ttl) then
+	redis.call('PEXPIRE', KEYS[1], ttl)
+end
+
+return 0
+`)
+
+// removes the tag set and returns its keys at once,
+// so that keys added while the tag is invalidated stay in the new set.
+//
+//nolint:gochecknoglobals // scripts are compiled once.
+var drainTagScript = redis.NewScript(`
+local keys = redis.call('SMEMBERS', KEYS[1])
+redis.call('DEL', KEYS[1])
+return keys
+`)
+
+// increments the key, setting expiry only if the key was created by this call.
+//
+//nolint:gochecknoglobals // scripts are compiled once.
+var incrScript = redis.NewScript(bumpVersionLua + `
+local existed = redis.call('EXISTS', KEYS[1])
+local res = redis.call('INCRBY', KEYS[1], ARGV[1])
+
+if existed == 0 and tonumber(ARGV[2]) > 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+
+bumpVersion()
+
+return res
+`)
+
+// bucket state is a hash with the token count and the time of the last refill in milliseconds.
+// server time is used so that clients with skewed clocks share the same buckets.
+// returns whether tokens were taken, remaining whole tokens and milliseconds until n tokens are available.
+//
+//nolint:gochecknoglobals // scripts are compiled once.
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local n = tonumber(ARGV[3])
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or burst
+local ts = tonumber(state[2]) or now
+
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate / 1000)
+
+local taken = 0
+local retry = 0
+
+if tokens >= n then
+	tokens = tokens - n
+	taken = 1
+else
+	retry = math.ceil((n - tokens) * 1000 / rate)
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst * 1000 / rate))
+
+return {taken, math.floor(tokens), retry}
+`)
+
+// every write bumps the version counter kept in the second key, which expires together with the value.
+// a missing counter is seeded from server time, so that versions do not repeat after the value is recreated.
+const bumpVersionLua = `
+local function bumpVersion()
+	if redis.call('EXISTS', KEYS[2]) == 1 then
+		redis.call('INCR', KEYS[2])
+	else
+		local time = redis.call('TIME')
+		redis.call('SET', KEYS[2], string.format('%.0f', tonumber(time[1]) * 1000000 + tonumber(time[2])))
+	end
+
+	local ttl = redis.call('PTTL', KEYS[1])
+	if ttl > 0 then
+		redis.call('PEXPIRE', KEYS[2], ttl)
+	else
+		redis.call('PERSIST', KEYS[2])
+	end
+end
+`
+
+// sets the value of the first key, keeping its expiry if ttl is negative, see lifetimeArg.
+const setValueLua = `
+local function setValue(value, ttl)
+	ttl = tonumber(ttl)
+	if ttl > 0 then
+		redis.call('SET', KEYS[1], value, 'PX', ttl)
+	elseif ttl < 0 then
+		redis.call('SET', KEYS[1], value, 'KEEPTTL')
+	else
+		redis.call('SET', KEYS[1], value)
+	end
+end
+`
+
+// values without a version counter, e.g. written by other clients, have version 0.
+const noVersion = "0"
+
+//nolint:gochecknoglobals // scripts are compiled once.
+var (
+	setScript = redis.NewScript(bumpVersionLua + setValueLua + `
+setValue(ARGV[1], ARGV[2])
+
+bumpVersion()
+
+return 1
+`)
+	setNXScript = redis.NewScript(bumpVersionLua + setValueLua + `
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return 0
+end
+
+setValue(ARGV[1], ARGV[2])
+
+bumpVersion()
+
+return 1
+`)
+	// returns -1 if the key does not exist, 0 if the version does not match and 1 if the value was swapped.
+	casScript = redis.NewScript(bumpVersionLua + setValueLua + `
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return -1
+end
+
+if (redis.call('GET', KEYS[2]) or '` + noVersion + `') ~= ARGV[1] then
+	return 0
+end
+
+setValue(ARGV[2], ARGV[3])
+
+bumpVersion()
+
+return 1
+`)
+)
+
 //nolint:gochecknoinits // driver pattern.
 func init() {
 	cache.Register("redis", newRedis)
 }
 
 type Cache struct {
-	c      *redis.Client
+	c      redis.UniversalClient
 	prefix string
 }
 
@@ -29,27 +187,40 @@ func newRedis(ctx context.Context, connurl string) (cache.Cache, error) {
 		return nil, errorx.Wrap(err, "parse url")
 	}
 
-	c, err := gredis.New(ctx, connurl)
+	c, err := gredis.NewShared(ctx, connurl)
 	if err != nil {
 		return nil, errorx.Wrap(err, "create redis client")
 	}
 
-	err = c.Ping(ctx).Err()
+	return New(c, u.Fragment), nil
+}
 
+// New creates a cache over an existing client, keys are prefixed with prefix.
+func New(c redis.UniversalClient, prefix string) *Cache {
 	return &Cache{
 		c:      c,
-		prefix: u.Fragment,
-	}, errorx.Wrap(err, "send ping")
+		prefix: prefix,
+	}
+}
+
+// Close closes the underlying client.
+func (c *Cache) Close() error {
+	return errorx.Wrap(c.c.Close(), "close redis client")
 }
 
 func (c *Cache) Set(ctx context.Context, key string, value []byte, lifetime time.Duration) error {
-	err := c.c.Set(ctx, c.prefix+":"+key, value, lifetime).Err()
+	ttl, err := lifetimeArg(lifetime)
+	if err != nil {
+		return err
+	}
+
+	err = setScript.Run(ctx, c.c, c.keys(key), value, ttl).Err()
 
 	return errorx.Wrap(err, "set value")
 }
 
 func (c *Cache) Get(ctx context.Context, key string) ([]byte, error) {
-	val, err := c.c.Get(ctx, c.prefix+":"+key).Result()
+	val, err := c.c.Get(ctx, c.key(key)).Result()
 	if err != nil {
 		if errors.Is(err, redis.Nil) {
 			return nil, cache.ErrNotExist
@@ -60,3 +231,199 @@ func (c *Cache) Get(ctx context.Context, key string) ([]byte, error) {
 
 	return []byte(val), nil
 }
+
+func (c *Cache) GetWithTTL(ctx context.Context, key string) ([]byte, time.Duration, error) {
+	key = c.key(key)
+
+	var (
+		get  *redis.StringCmd
+		pttl *redis.DurationCmd
+	)
+
+	_, err := c.c.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		get = p.Get(ctx, key)
+		pttl = p.PTTL(ctx, key)
+
+		return nil
+	})
+	if errors.Is(err, redis.Nil) {
+		return nil, 0, cache.ErrNotExist
+	} else if err != nil {
+		return nil, 0, errorx.Wrap(err, "get value with ttl")
+	}
+
+	// negative durations mean the key has no expiry.
+	return []byte(get.Val()), max(pttl.Val(), 0), nil
+}
+
+// tag sets hold full keys of tagged values and live at least as long as the longest of them.
+// keys that were overwritten or expired stay in the set until it expires or is invalidated.
+func (c *Cache) SetWithTags(ctx context.Context, key string, value []byte, lifetime time.Duration,
+	tags ...string,
+) error {
+	ttl, err := lifetimeArg(lifetime)
+	if err != nil {
+		return err
+	}
+
+	keys := c.keys(key)
+
+	_, err = c.c.Pipelined(ctx, func(p redis.Pipeliner) error {
+		setScript.Eval(ctx, p, keys, value, ttl)
+
+		for _, tag := range tags {
+			tagScript.Eval(ctx, p, []string{c.tagKey(tag)}, keys[0], ttl)
+		}
+
+		return nil
+	})
+
+	return errorx.Wrap(err, "set tagged value")
+}
+
+func (c *Cache) InvalidateTags(ctx context.Context, tags ...string) error {
+	for _, tag := range tags {
+		tk := c.tagKey(tag)
+
+		keys, err := drainTagScript.Run(ctx, c.c, []string{tk}).StringSlice()
+		if err != nil {
+			return errorx.Wrap(err, "get keys for tag %s", tag)
+		}
+
+		if len(keys) == 0 {
+			continue
+		}
+
+		// keys are deleted one by one so that they do not have to share a slot.
+		_, err = c.c.Pipelined(ctx, func(p redis.Pipeliner) error {
+			for _, key := range keys {
+				p.Unlink(ctx, key, versionKey(key))
+			}
+
+			return nil
+		})
+		if err != nil {
+			return errorx.Wrap(err, "delete keys for tag %s", tag)
+		}
+	}
+
+	return nil
+}
+
+func (c *Cache) IncrBy(ctx context.Context, key string, delta int64, lifetime time.Duration) (int64, error) {
+	ttl, err := lifetimeArg(lifetime)
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := incrScript.Run(ctx, c.c, c.keys(key), delta, ttl).Int64()
+
+	return res, errorx.Wrap(err, "increment value")
+}
+
+func (c *Cache) TakeTokens(ctx context.Context, key string, rate float64, burst, n int64) (cache.Tokens, error) {
+	if rate <= 0 || burst <= 0 {
+		return cache.Tokens{}, errorx.IllegalArgument.New("rate and burst must be positive, got %v and %d", rate, burst)
+	}
+
+	res, err := tokenBucketScript.Run(ctx, c.c, []string{c.key(key)}, rate, burst, n).Int64Slice()
+	if err != nil {
+		return cache.Tokens{}, errorx.Wrap(err, "take tokens")
+	}
+
+	//nolint:mnd // script result.
+	if len(res) != 3 {
+		return cache.Tokens{}, errorx.IllegalState.New("unexpected token bucket script result %v", res)
+	}
+
+	return cache.Tokens{
+		Taken:      res[0] == 1,
+		Remaining:  res[1],
+		RetryAfter: time.Duration(res[2]) * time.Millisecond,
+	}, nil
+}
+
+func (c *Cache) SetIfNotExists(ctx context.Context, key string, value []byte, lifetime time.Duration) (bool, error) {
+	ttl, err := lifetimeArg(lifetime)
+	if err != nil {
+		return false, err
+	}
+
+	res, err := setNXScript.Run(ctx, c.c, c.keys(key), value, ttl).Bool()
+
+	return res, errorx.Wrap(err, "set value if not exists")
+}
+
+func (c *Cache) GetVersioned(ctx context.Context, key string) ([]byte, string, error) {
+	res, err := c.c.MGet(ctx, c.keys(key)...).Result()
+	if err != nil {
+		return nil, "", errorx.Wrap(err, "get versioned value")
+	}
+
+	val, ok := res[0].(string)
+	if !ok {
+		return nil, "", cache.ErrNotExist
+	}
+
+	version, ok := res[1].(string)
+	if !ok {
+		version = noVersion
+	}
+
+	return []byte(val), version, nil
+}
+
+func (c *Cache) CompareAndSwap(ctx context.Context, key, oldVersion string, value []byte,
+	lifetime time.Duration,
+) (bool, error) {
+	ttl, err := lifetimeArg(lifetime)
+	if err != nil {
+		return false, err
+	}
+
+	res, err := casScript.Run(ctx, c.c, c.keys(key), oldVersion, value, ttl).Int64()
+	if err != nil {
+		return false, errorx.Wrap(err, "compare and swap value")
+	}
+
+	if res < 0 {
+		return false, cache.ErrNotExist
+	}
+
+	return res == 1, nil
+}
+
+func (c *Cache) key(key string) string {
+	return c.prefix + ":" + key
+}
+
+// keys returns the value key and its version key.
+func (c *Cache) keys(key string) []string {
+	key = c.key(key)
+
+	return []string{key, versionKey(key)}
+}
+
+func (c *Cache) tagKey(tag string) string {
+	return c.prefix + ":__tags:" + tag
+}
+
+// versionKey returns the key of the version counter in the same cluster slot as key.
+func versionKey(key string) string {
+	return gredis.SlotKey(key, ":__version")
+}
+
+// lifetimeArg converts lifetime to milliseconds passed to scripts, sub-millisecond ones are rounded up like go-redis does.
+// redis.KeepTTL is passed as -1, other negative lifetimes are rejected.
+func lifetimeArg(lifetime time.Duration) (int64, error) {
+	switch {
+	case lifetime == redis.KeepTTL:
+		return -1, nil
+	case lifetime < 0:
+		return 0, errorx.IllegalArgument.New("lifetime must not be negative, got %s", lifetime)
+	case lifetime > 0 && lifetime < time.Millisecond:
+		return 1, nil
+	default:
+		return lifetime.Milliseconds(), nil
+	}
+}
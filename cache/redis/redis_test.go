@@ -0,0 +1,155 @@
+package redis_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/sovamorco/gommon/cache"
+	"github.com/sovamorco/gommon/cache/redis"
+	"github.com/sovamorco/gommon/gredis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCache(t *testing.T) (*redis.Cache, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+
+	cl, err := gredis.NewClient("redis://" + mr.Addr())
+	require.NoError(t, err)
+
+	c := redis.New(cl, "test")
+	t.Cleanup(func() { _ = c.Close() })
+
+	return c, mr
+}
+
+func TestSetIfNotExists(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c, mr := newCache(t)
+
+	ok, err := c.SetIfNotExists(ctx, "key", []byte("first"), time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = c.SetIfNotExists(ctx, "key", []byte("second"), time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	res, err := c.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("first"), res)
+
+	mr.FastForward(2 * time.Minute)
+
+	ok, err = c.SetIfNotExists(ctx, "key", []byte("third"), time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestCompareAndSwap(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c, _ := newCache(t)
+
+	_, err := c.CompareAndSwap(ctx, "key", "", []byte("value"), time.Minute)
+	require.ErrorIs(t, err, cache.ErrNotExist)
+
+	require.NoError(t, c.Set(ctx, "key", []byte("a"), time.Minute))
+
+	_, version, err := c.GetVersioned(ctx, "key")
+	require.NoError(t, err)
+
+	ok, err := c.CompareAndSwap(ctx, "key", version, []byte("b"), time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = c.CompareAndSwap(ctx, "key", version, []byte("c"), time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, version, err = c.GetVersioned(ctx, "key")
+	require.NoError(t, err)
+
+	// value is changed and changed back, version has to differ anyway.
+	require.NoError(t, c.Set(ctx, "key", []byte("a"), time.Minute))
+	require.NoError(t, c.Set(ctx, "key", []byte("b"), time.Minute))
+
+	ok, err = c.CompareAndSwap(ctx, "key", version, []byte("c"), time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	res, err := c.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("b"), res)
+}
+
+func TestGetWithTTL(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c, _ := newCache(t)
+
+	_, _, err := c.GetWithTTL(ctx, "key")
+	require.ErrorIs(t, err, cache.ErrNotExist)
+
+	require.NoError(t, c.Set(ctx, "key", []byte("value"), time.Minute))
+
+	res, ttl, err := c.GetWithTTL(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value"), res)
+	assert.InDelta(t, time.Minute, ttl, float64(time.Second))
+
+	require.NoError(t, c.Set(ctx, "forever", []byte("value"), 0))
+
+	_, ttl, err = c.GetWithTTL(ctx, "forever")
+	require.NoError(t, err)
+	assert.Zero(t, ttl)
+}
+
+func TestInvalidateTags(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c, mr := newCache(t)
+
+	require.NoError(t, c.SetWithTags(ctx, "first", []byte("value"), time.Minute, "tag"))
+	require.NoError(t, c.SetWithTags(ctx, "second", []byte("value"), 0, "tag", "other"))
+
+	require.NoError(t, c.InvalidateTags(ctx, "tag", "missing"))
+
+	for _, key := range []string{"first", "second"} {
+		_, err := c.Get(ctx, key)
+		require.ErrorIs(t, err, cache.ErrNotExist, key)
+	}
+
+	assert.False(t, mr.Exists("test:__tags:tag"))
+	assert.True(t, mr.Exists("test:__tags:other"))
+}
+
+func TestLifetime(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c, mr := newCache(t)
+
+	require.NoError(t, c.Set(ctx, "short", []byte("value"), time.Microsecond))
+	assert.Equal(t, time.Millisecond, mr.TTL("test:short"))
+
+	require.NoError(t, c.Set(ctx, "key", []byte("value"), time.Minute))
+	require.NoError(t, c.Set(ctx, "key", []byte("new"), goredis.KeepTTL))
+	assert.Equal(t, time.Minute, mr.TTL("test:key"))
+
+	res, err := c.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("new"), res)
+
+	require.Error(t, c.Set(ctx, "key", []byte("value"), -time.Second))
+}
@@ -0,0 +1,180 @@
+package cache
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/sovamorco/errorx"
+)
+
+const (
+	// DefaultXFetchBeta is the beta recommended by the XFetch paper.
+	// values above 1 favor earlier recomputation, values below 1 favor later.
+	DefaultXFetchBeta = 1.0
+
+	swrFormatVersion = 1
+	// version byte, soft expiry in unix nanoseconds, recompute delta in nanoseconds.
+	swrHeaderSize = 1 + 8 + 8
+)
+
+var errInvalidSWREntry = errors.New("value is not a stale-while-revalidate entry")
+
+type Loader func(ctx context.Context) ([]byte, error)
+
+// SWR stores entries with a soft and a hard expiry on top of any Cache.
+// reads past the soft expiry return the stale value and refresh it in background,
+// reads before the soft expiry may refresh early using XFetch probabilistic early expiration.
+// the hard expiry is the lifetime of the entry in the underlying cache.
+type SWR struct {
+	c    Cache
+	beta float64
+
+	refreshing sync.Map `exhaustruct:"optional"`
+}
+
+func NewSWR(c Cache, beta float64) *SWR {
+	return &SWR{
+		c:    c,
+		beta: beta,
+	}
+}
+
+type swrEntry struct {
+	softExpiry time.Time
+	delta      time.Duration
+	value      []byte
+}
+
+// Set stores value that is fresh for freshFor and is served stale until staleFor ends.
+func (s *SWR) Set(ctx context.Context, key string, value []byte, freshFor, staleFor time.Duration) error {
+	return s.set(ctx, key, value, freshFor, staleFor, 0)
+}
+
+// Get returns the value stored by Set or Fetch and whether it is past its soft expiry.
+// it never triggers a refresh.
+func (s *SWR) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	e, err := s.get(ctx, key)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return e.value, !time.Now().Before(e.softExpiry), nil
+}
+
+// Fetch returns the cached value for key, calling load synchronously if there is none.
+// stale values and values picked for early expiration are returned right away
+// while load is called in background to refresh them.
+func (s *SWR) Fetch(ctx context.Context, key string, freshFor, staleFor time.Duration, load Loader) ([]byte, error) {
+	e, err := s.get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, ErrNotExist) {
+			zerolog.Ctx(ctx).Warn().Err(err).Str("key", key).Msg("Failed to get cached value, reloading")
+		}
+
+		return s.load(ctx, key, freshFor, staleFor, load)
+	}
+
+	if s.shouldRefresh(e, time.Now()) {
+		s.refresh(ctx, key, freshFor, staleFor, load)
+	}
+
+	return e.value, nil
+}
+
+// shouldRefresh implements XFetch: an entry is recomputed early with probability
+// growing as the soft expiry approaches, scaled by how long it took to compute.
+func (s *SWR) shouldRefresh(e swrEntry, now time.Time) bool {
+	if !now.Before(e.softExpiry) {
+		return true
+	}
+
+	// 1 - Float64 is in (0, 1], so the logarithm is never infinite.
+	gap := -float64(e.delta) * s.beta * math.Log(1-rand.Float64()) //nolint:gosec // no need for crypto rand.
+
+	return !now.Add(time.Duration(gap)).Before(e.softExpiry)
+}
+
+func (s *SWR) refresh(ctx context.Context, key string, freshFor, staleFor time.Duration, load Loader) {
+	if _, running := s.refreshing.LoadOrStore(key, struct{}{}); running {
+		return
+	}
+
+	// refresh has to outlive the request that triggered it.
+	ctx = context.WithoutCancel(ctx)
+
+	go func() {
+		defer s.refreshing.Delete(key)
+
+		_, err := s.load(ctx, key, freshFor, staleFor, load)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Str("key", key).Msg("Failed to refresh cached value")
+		}
+	}()
+}
+
+func (s *SWR) load(ctx context.Context, key string, freshFor, staleFor time.Duration, load Loader) ([]byte, error) {
+	start := time.Now()
+
+	value, err := load(ctx)
+	if err != nil {
+		return nil, errorx.Wrap(err, "load value")
+	}
+
+	err = s.set(ctx, key, value, freshFor, staleFor, time.Since(start))
+	if err != nil {
+		return nil, errorx.Wrap(err, "set loaded value")
+	}
+
+	return value, nil
+}
+
+func (s *SWR) set(ctx context.Context, key string, value []byte, freshFor, staleFor, delta time.Duration) error {
+	e := swrEntry{
+		softExpiry: time.Now().Add(freshFor),
+		delta:      delta,
+		value:      value,
+	}
+
+	err := s.c.Set(ctx, key, encodeSWREntry(e), freshFor+staleFor)
+
+	return errorx.Wrap(err, "set entry")
+}
+
+func (s *SWR) get(ctx context.Context, key string) (swrEntry, error) {
+	raw, err := s.c.Get(ctx, key)
+	if err != nil {
+		return swrEntry{}, errorx.Wrap(err, "get entry")
+	}
+
+	e, err := decodeSWREntry(raw)
+
+	return e, errorx.Wrap(err, "decode entry")
+}
+
+func encodeSWREntry(e swrEntry) []byte {
+	res := make([]byte, swrHeaderSize, swrHeaderSize+len(e.value))
+
+	res[0] = swrFormatVersion
+	binary.BigEndian.PutUint64(res[1:9], uint64(e.softExpiry.UnixNano())) //nolint:gosec // time after epoch.
+	binary.BigEndian.PutUint64(res[9:17], uint64(e.delta))                //nolint:gosec // durations are positive.
+
+	return append(res, e.value...)
+}
+
+func decodeSWREntry(raw []byte) (swrEntry, error) {
+	if len(raw) < swrHeaderSize || raw[0] != swrFormatVersion {
+		return swrEntry{}, errInvalidSWREntry
+	}
+
+	return swrEntry{
+		softExpiry: time.Unix(0, int64(binary.BigEndian.Uint64(raw[1:9]))), //nolint:gosec // written by encodeSWREntry.
+		delta:      time.Duration(binary.BigEndian.Uint64(raw[9:17])),      //nolint:gosec // written by encodeSWREntry.
+		value:      raw[swrHeaderSize:],
+	}, nil
+}
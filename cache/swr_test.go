@@ -0,0 +1,75 @@
+package cache_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sovamorco/gommon/cache"
+	_ "github.com/sovamorco/gommon/cache/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMockCache(t *testing.T) cache.Cache {
+	t.Helper()
+
+	c, err := cache.New(context.Background(), cache.Config{Provider: "mock", URL: "", Compression: nil, Encryption: nil, Local: nil})
+	require.NoError(t, err)
+
+	return c
+}
+
+func TestSWRFetch(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	swr := cache.NewSWR(newMockCache(t), cache.DefaultXFetchBeta)
+
+	var calls atomic.Int32
+
+	load := func(context.Context) ([]byte, error) {
+		n := calls.Add(1)
+
+		return []byte{byte(n)}, nil
+	}
+
+	res, err := swr.Fetch(ctx, "key", 50*time.Millisecond, time.Minute, load)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1}, res)
+
+	res, err = swr.Fetch(ctx, "key", 50*time.Millisecond, time.Minute, load)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1}, res)
+	assert.Equal(t, int32(1), calls.Load())
+
+	time.Sleep(60 * time.Millisecond)
+
+	// stale value is returned right away and refreshed in background.
+	res, err = swr.Fetch(ctx, "key", 50*time.Millisecond, time.Minute, load)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1}, res)
+
+	assert.Eventually(t, func() bool {
+		res, stale, err := swr.Get(ctx, "key")
+
+		return err == nil && !stale && res[0] == 2
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestSWRLegacyValue(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c := newMockCache(t)
+	swr := cache.NewSWR(c, cache.DefaultXFetchBeta)
+
+	require.NoError(t, c.Set(ctx, "key", []byte("plain"), time.Minute))
+
+	res, err := swr.Fetch(ctx, "key", time.Minute, time.Minute, func(context.Context) ([]byte, error) {
+		return []byte("loaded"), nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("loaded"), res)
+}
@@ -0,0 +1,264 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/sovamorco/errorx"
+	"github.com/sovamorco/gommon/broker"
+)
+
+const (
+	DefaultLocalSize                = 1024
+	DefaultLocalTTL                 = 5 * time.Second
+	DefaultLocalInvalidationChannel = "cache-invalidations"
+)
+
+type LocalConfig struct {
+	// maximum number of entries kept in memory.
+	Size int `mapstructure:"size"`
+	// lifetime of local entries, capped by the lifetime passed to Set.
+	TTL time.Duration `mapstructure:"ttl"`
+	// broker used to publish invalidations to other instances, none if nil.
+	Invalidation *broker.Config `mapstructure:"invalidation"`
+	Channel      string         `mapstructure:"channel"`
+}
+
+// Tiered is a two-tier cache with a bounded local L1 in front of a remote L2.
+// writes go to both tiers and, if a broker is set, evict the key from L1 of other instances.
+type Tiered struct {
+	local   *local
+	remote  Cache
+	ttl     time.Duration
+	b       broker.Broker
+	channel string
+	// used to ignore invalidations published by this instance.
+	origin string
+	// stops the invalidation subscription.
+	cancel context.CancelFunc
+	// whether b was created by the tiered cache and has to be shut down with it.
+	ownsBroker bool
+}
+
+type invalidation struct {
+	Origin string   `json:"origin"`
+	Keys   []string `json:"keys"`
+	// local tiers do not know tags of entries loaded from remote, so tag invalidations clear them fully.
+	Purge bool `json:"purge"`
+}
+
+// NewTiered creates a two-tier cache over remote.
+// b can be nil, in which case other instances only see writes after their local entries expire.
+// ctx bounds the lifetime of the invalidation subscription, see also Close.
+func NewTiered(ctx context.Context, remote Cache, b broker.Broker, cfg LocalConfig) *Tiered {
+	if cfg.Size <= 0 {
+		cfg.Size = DefaultLocalSize
+	}
+
+	if cfg.TTL <= 0 {
+		cfg.TTL = DefaultLocalTTL
+	}
+
+	if cfg.Channel == "" {
+		cfg.Channel = DefaultLocalInvalidationChannel
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	t := &Tiered{
+		local:      newLocal(cfg.Size),
+		remote:     remote,
+		ttl:        cfg.TTL,
+		b:          b,
+		channel:    cfg.Channel,
+		origin:     uuid.New().String(),
+		cancel:     cancel,
+		ownsBroker: false,
+	}
+
+	if b != nil {
+		b.Subscribe(ctx, broker.StructToMessageHandler(t.handleInvalidation), cfg.Channel)
+	}
+
+	return t
+}
+
+func newTieredFromConfig(ctx context.Context, remote Cache, cfg LocalConfig) (*Tiered, error) {
+	var b broker.Broker
+
+	if cfg.Invalidation != nil {
+		var err error
+
+		b, err = broker.New(ctx, *cfg.Invalidation)
+		if err != nil {
+			return nil, errorx.Wrap(err, "create invalidation broker")
+		}
+	}
+
+	t := NewTiered(ctx, remote, b, cfg)
+	t.ownsBroker = b != nil
+
+	return t, nil
+}
+
+// Close stops the invalidation subscription and shuts down the broker if it was created from LocalConfig.
+// the remote cache is left open.
+func (t *Tiered) Close() error {
+	t.cancel()
+
+	if t.ownsBroker {
+		t.b.Shutdown(context.Background())
+	}
+
+	return nil
+}
+
+func (t *Tiered) Set(ctx context.Context, key string, value []byte, lifetime time.Duration) error {
+	err := t.remote.Set(ctx, key, value, lifetime)
+	if err != nil {
+		return errorx.Wrap(err, "set remote value")
+	}
+
+	t.local.set(key, value, t.localLifetime(lifetime))
+
+	return t.invalidate(ctx, key)
+}
+
+func (t *Tiered) Get(ctx context.Context, key string) ([]byte, error) {
+	if v, ok := t.local.get(key); ok {
+		return v, nil
+	}
+
+	v, ttl, err := GetWithTTL(ctx, t.remote, key)
+	if err != nil {
+		return nil, errorx.Wrap(err, "get remote value")
+	}
+
+	// local entries must not outlive the remote value.
+	t.local.set(key, v, t.localLifetime(ttl))
+
+	return v, nil
+}
+
+func (t *Tiered) SetWithTags(ctx context.Context, key string, value []byte, lifetime time.Duration,
+	tags ...string,
+) error {
+	err := t.remote.SetWithTags(ctx, key, value, lifetime, tags...)
+	if err != nil {
+		return errorx.Wrap(err, "set remote value")
+	}
+
+	t.local.set(key, value, t.localLifetime(lifetime))
+
+	return t.invalidate(ctx, key)
+}
+
+func (t *Tiered) InvalidateTags(ctx context.Context, tags ...string) error {
+	err := t.remote.InvalidateTags(ctx, tags...)
+	if err != nil {
+		return errorx.Wrap(err, "invalidate remote tags")
+	}
+
+	t.local.purge()
+
+	return t.publish(ctx, invalidation{
+		Origin: t.origin,
+		Keys:   nil,
+		Purge:  true,
+	})
+}
+
+func (t *Tiered) IncrBy(ctx context.Context, key string, delta int64, lifetime time.Duration) (int64, error) {
+	res, err := t.remote.IncrBy(ctx, key, delta, lifetime)
+	if err != nil {
+		return 0, errorx.Wrap(err, "increment remote value")
+	}
+
+	// counters change too often to be worth keeping locally.
+	t.local.delete(key)
+
+	return res, t.invalidate(ctx, key)
+}
+
+func (t *Tiered) SetIfNotExists(ctx context.Context, key string, value []byte, lifetime time.Duration) (bool, error) {
+	set, err := t.remote.SetIfNotExists(ctx, key, value, lifetime)
+	if err != nil || !set {
+		return set, errorx.Wrap(err, "set remote value if not exists")
+	}
+
+	t.local.set(key, value, t.localLifetime(lifetime))
+
+	return true, t.invalidate(ctx, key)
+}
+
+// GetVersioned always reads from remote, since local entries do not hold versions.
+func (t *Tiered) GetVersioned(ctx context.Context, key string) ([]byte, string, error) {
+	v, version, err := t.remote.GetVersioned(ctx, key)
+
+	return v, version, errorx.Wrap(err, "get remote value")
+}
+
+func (t *Tiered) CompareAndSwap(ctx context.Context, key, oldVersion string, value []byte,
+	lifetime time.Duration,
+) (bool, error) {
+	swapped, err := t.remote.CompareAndSwap(ctx, key, oldVersion, value, lifetime)
+	if err != nil || !swapped {
+		return swapped, errorx.Wrap(err, "compare and swap remote value")
+	}
+
+	t.local.set(key, value, t.localLifetime(lifetime))
+
+	return true, t.invalidate(ctx, key)
+}
+
+func (t *Tiered) TakeTokens(ctx context.Context, key string, rate float64, burst, n int64) (Tokens, error) {
+	return TakeTokens(ctx, t.remote, key, rate, burst, n)
+}
+
+func (t *Tiered) localLifetime(lifetime time.Duration) time.Duration {
+	if lifetime > 0 && lifetime < t.ttl {
+		return lifetime
+	}
+
+	return t.ttl
+}
+
+// invalidate evicts keys from local tiers of other instances.
+func (t *Tiered) invalidate(ctx context.Context, keys ...string) error {
+	return t.publish(ctx, invalidation{
+		Origin: t.origin,
+		Keys:   keys,
+		Purge:  false,
+	})
+}
+
+func (t *Tiered) publish(ctx context.Context, inv invalidation) error {
+	if t.b == nil {
+		return nil
+	}
+
+	err := t.b.Publish(ctx, t.channel, inv)
+
+	return errorx.Wrap(err, "publish invalidation")
+}
+
+func (t *Tiered) handleInvalidation(ctx context.Context, _ string, inv invalidation) error {
+	if inv.Origin == t.origin {
+		return nil
+	}
+
+	zerolog.Ctx(ctx).Trace().Strs("keys", inv.Keys).Bool("purge", inv.Purge).
+		Msg("Invalidating local cache entries")
+
+	if inv.Purge {
+		t.local.purge()
+
+		return nil
+	}
+
+	t.local.delete(inv.Keys...)
+
+	return nil
+}
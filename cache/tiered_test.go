@@ -0,0 +1,96 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/sovamorco/gommon/broker"
+	_ "github.com/sovamorco/gommon/broker/mock"
+	"github.com/sovamorco/gommon/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTieredInvalidation(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	remote := newMockCache(t)
+
+	b, err := broker.New(ctx, broker.Config{Provider: "mock", URL: ""})
+	require.NoError(t, err)
+
+	cfg := cache.LocalConfig{Size: 10, TTL: time.Minute, Invalidation: nil, Channel: ""}
+	first := cache.NewTiered(ctx, remote, b, cfg)
+	second := cache.NewTiered(ctx, remote, b, cfg)
+
+	t.Cleanup(func() {
+		_ = first.Close()
+		_ = second.Close()
+	})
+
+	require.NoError(t, first.Set(ctx, "key", []byte("old"), time.Minute))
+
+	res, err := second.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("old"), res)
+
+	require.NoError(t, first.Set(ctx, "key", []byte("new"), time.Minute))
+
+	assert.Eventually(t, func() bool {
+		res, err := second.Get(ctx, "key")
+
+		return err == nil && string(res) == "new"
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestTieredRemoteTTL(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	remote := newMockCache(t)
+
+	cfg := cache.LocalConfig{Size: 10, TTL: time.Minute, Invalidation: nil, Channel: ""}
+	tiered := cache.NewTiered(ctx, remote, nil, cfg)
+
+	t.Cleanup(func() { _ = tiered.Close() })
+
+	require.NoError(t, remote.Set(ctx, "key", []byte("value"), 50*time.Millisecond))
+
+	res, err := tiered.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value"), res)
+
+	assert.Eventually(t, func() bool {
+		_, err := tiered.Get(ctx, "key")
+
+		return errors.Is(err, cache.ErrNotExist)
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestTieredFromConfig(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	c, err := cache.New(ctx, cache.Config{
+		Provider:    "mock",
+		URL:         "",
+		Compression: nil,
+		Encryption:  nil,
+		Local: &cache.LocalConfig{
+			Size:         10,
+			TTL:          time.Minute,
+			Invalidation: &broker.Config{Provider: "mock", URL: ""},
+			Channel:      "",
+		},
+	})
+	require.NoError(t, err)
+
+	closer, ok := c.(io.Closer)
+	require.True(t, ok)
+	require.NoError(t, closer.Close())
+}
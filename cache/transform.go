@@ -0,0 +1,129 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/sovamorco/errorx"
+)
+
+// Transformer encodes values before they are written and decodes them after they are read.
+// Decode has to return values that were not produced by Encode unchanged,
+// so that values written before the transformer was enabled can still be read.
+type Transformer interface {
+	Encode(value []byte) ([]byte, error)
+	Decode(value []byte) ([]byte, error)
+}
+
+// Transformed applies a Transformer to every value of the underlying cache.
+// counters and token buckets are passed through as is.
+type Transformed struct {
+	c Cache
+	t Transformer
+}
+
+func Transform(c Cache, t Transformer) *Transformed {
+	return &Transformed{
+		c: c,
+		t: t,
+	}
+}
+
+func (t *Transformed) Set(ctx context.Context, key string, value []byte, lifetime time.Duration) error {
+	enc, err := t.t.Encode(value)
+	if err != nil {
+		return errorx.Wrap(err, "encode value")
+	}
+
+	return errorx.Wrap(t.c.Set(ctx, key, enc, lifetime), "set encoded value")
+}
+
+func (t *Transformed) Get(ctx context.Context, key string) ([]byte, error) {
+	enc, err := t.c.Get(ctx, key)
+	if err != nil {
+		return nil, errorx.Wrap(err, "get encoded value")
+	}
+
+	v, err := t.t.Decode(enc)
+
+	return v, errorx.Wrap(err, "decode value")
+}
+
+func (t *Transformed) GetWithTTL(ctx context.Context, key string) ([]byte, time.Duration, error) {
+	enc, ttl, err := GetWithTTL(ctx, t.c, key)
+	if err != nil {
+		return nil, 0, errorx.Wrap(err, "get encoded value")
+	}
+
+	v, err := t.t.Decode(enc)
+	if err != nil {
+		return nil, 0, errorx.Wrap(err, "decode value")
+	}
+
+	return v, ttl, nil
+}
+
+func (t *Transformed) SetWithTags(ctx context.Context, key string, value []byte, lifetime time.Duration,
+	tags ...string,
+) error {
+	enc, err := t.t.Encode(value)
+	if err != nil {
+		return errorx.Wrap(err, "encode value")
+	}
+
+	return errorx.Wrap(t.c.SetWithTags(ctx, key, enc, lifetime, tags...), "set encoded value")
+}
+
+func (t *Transformed) InvalidateTags(ctx context.Context, tags ...string) error {
+	return errorx.Wrap(t.c.InvalidateTags(ctx, tags...), "invalidate tags")
+}
+
+func (t *Transformed) IncrBy(ctx context.Context, key string, delta int64, lifetime time.Duration) (int64, error) {
+	res, err := t.c.IncrBy(ctx, key, delta, lifetime)
+
+	return res, errorx.Wrap(err, "increment value")
+}
+
+func (t *Transformed) SetIfNotExists(ctx context.Context, key string, value []byte,
+	lifetime time.Duration,
+) (bool, error) {
+	enc, err := t.t.Encode(value)
+	if err != nil {
+		return false, errorx.Wrap(err, "encode value")
+	}
+
+	set, err := t.c.SetIfNotExists(ctx, key, enc, lifetime)
+
+	return set, errorx.Wrap(err, "set encoded value if not exists")
+}
+
+func (t *Transformed) GetVersioned(ctx context.Context, key string) ([]byte, string, error) {
+	enc, version, err := t.c.GetVersioned(ctx, key)
+	if err != nil {
+		return nil, "", errorx.Wrap(err, "get encoded value")
+	}
+
+	v, err := t.t.Decode(enc)
+	if err != nil {
+		return nil, "", errorx.Wrap(err, "decode value")
+	}
+
+	return v, version, nil
+}
+
+func (t *Transformed) CompareAndSwap(ctx context.Context, key, oldVersion string, value []byte,
+	lifetime time.Duration,
+) (bool, error) {
+	enc, err := t.t.Encode(value)
+	if err != nil {
+		return false, errorx.Wrap(err, "encode value")
+	}
+
+	swapped, err := t.c.CompareAndSwap(ctx, key, oldVersion, enc, lifetime)
+
+	return swapped, errorx.Wrap(err, "compare and swap encoded value")
+}
+
+func (t *Transformed) TakeTokens(ctx context.Context, key string, rate float64, burst, n int64) (Tokens, error) {
+	return TakeTokens(ctx, t.c, key, rate, burst, n)
+}
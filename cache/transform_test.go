@@ -0,0 +1,62 @@
+package cache_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sovamorco/gommon/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransformers(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	raw := newMockCache(t)
+
+	oldEnc, err := cache.NewEncryptor(cache.EncryptionConfig{
+		KeyID: "old",
+		Keys:  map[string][]byte{"old": bytes.Repeat([]byte{1}, 32)},
+	})
+	require.NoError(t, err)
+
+	newEnc, err := cache.NewEncryptor(cache.EncryptionConfig{
+		KeyID: "new",
+		Keys: map[string][]byte{
+			"old": bytes.Repeat([]byte{1}, 32),
+			"new": bytes.Repeat([]byte{2}, 16),
+		},
+	})
+	require.NoError(t, err)
+
+	cmp, err := cache.NewCompressor(cache.CompressionConfig{Algorithm: cache.Zstd, Threshold: 16})
+	require.NoError(t, err)
+
+	large := bytes.Repeat([]byte("large value "), 100)
+
+	require.NoError(t, raw.Set(ctx, "legacy", []byte("plain"), time.Minute))
+	require.NoError(t, cache.Transform(raw, oldEnc).Set(ctx, "rotated", []byte("secret"), time.Minute))
+
+	c := cache.Transform(cache.Transform(raw, newEnc), cmp)
+
+	require.NoError(t, c.Set(ctx, "small", []byte("small"), time.Minute))
+	require.NoError(t, c.Set(ctx, "large", large, time.Minute))
+
+	stored, err := raw.Get(ctx, "large")
+	require.NoError(t, err)
+	assert.NotContains(t, string(stored), "large value")
+
+	for key, expected := range map[string][]byte{
+		"legacy":  []byte("plain"),
+		"rotated": []byte("secret"),
+		"small":   []byte("small"),
+		"large":   large,
+	} {
+		res, err := c.Get(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, expected, res, key)
+	}
+}
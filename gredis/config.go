@@ -0,0 +1,173 @@
+package gredis
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sovamorco/errorx"
+)
+
+// Config extends connection url with settings that cannot be set through it.
+// zero values keep settings from the url or go-redis defaults.
+type Config struct {
+	URL string `mapstructure:"url"`
+
+	PoolSize     int `mapstructure:"pool_size"`
+	MinIdleConns int `mapstructure:"min_idle_conns"`
+
+	DialTimeout  time.Duration `mapstructure:"dial_timeout"`
+	ReadTimeout  time.Duration `mapstructure:"read_timeout"`
+	WriteTimeout time.Duration `mapstructure:"write_timeout"`
+	// timeout of the initial ping, independent of the ctx passed to NewFromConfig.
+	PingTimeout time.Duration `mapstructure:"ping_timeout"`
+
+	MaxRetries      int           `mapstructure:"max_retries"`
+	MinRetryBackoff time.Duration `mapstructure:"min_retry_backoff"`
+	MaxRetryBackoff time.Duration `mapstructure:"max_retry_backoff"`
+
+	TLS *TLSConfig `mapstructure:"tls"`
+}
+
+// TLSConfig enables TLS, each of the certificates can be set either as a file path or as PEM bytes,
+// which config.LoadConfig decodes from base64 strings.
+type TLSConfig struct {
+	CAFile   string `mapstructure:"ca_file"`
+	CA       []byte `mapstructure:"ca"`
+	CertFile string `mapstructure:"cert_file"`
+	Cert     []byte `mapstructure:"cert"`
+	KeyFile  string `mapstructure:"key_file"`
+	Key      []byte `mapstructure:"key"`
+
+	// defaults to the host from the url.
+	ServerName         string `mapstructure:"server_name"`
+	InsecureSkipVerify bool   `mapstructure:"insecure_skip_verify"`
+}
+
+//nolint:ireturn // depends on url scheme.
+func NewFromConfig(ctx context.Context, cfg Config, opts ...Option) (redis.UniversalClient, error) {
+	co, err := parseURL(cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	err = cfg.apply(co.common())
+	if err != nil {
+		return nil, errorx.Wrap(err, "apply config")
+	}
+
+	rsc := co.client()
+
+	applyOptions(rsc, opts)
+
+	if cfg.PingTimeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, cfg.PingTimeout)
+		defer cancel()
+	}
+
+	err = rsc.Ping(ctx).Err()
+	if err != nil {
+		_ = rsc.Close()
+
+		return nil, errorx.Wrap(err, "ping redis")
+	}
+
+	return rsc, nil
+}
+
+func (cfg Config) apply(o commonOptions) error {
+	setIfNotZero(o.poolSize, cfg.PoolSize)
+	setIfNotZero(o.minIdleConns, cfg.MinIdleConns)
+	setIfNotZero(o.maxRetries, cfg.MaxRetries)
+	setIfNotZero(o.dialTimeout, cfg.DialTimeout)
+	setIfNotZero(o.readTimeout, cfg.ReadTimeout)
+	setIfNotZero(o.writeTimeout, cfg.WriteTimeout)
+	setIfNotZero(o.minRetryBackoff, cfg.MinRetryBackoff)
+	setIfNotZero(o.maxRetryBackoff, cfg.MaxRetryBackoff)
+
+	if cfg.TLS == nil {
+		return nil
+	}
+
+	tc, err := cfg.TLS.build(*o.tlsConfig)
+	if err != nil {
+		return errorx.Wrap(err, "build tls config")
+	}
+
+	*o.tlsConfig = tc
+
+	return nil
+}
+
+// build returns a tls config based on the one from the url, if any.
+func (cfg *TLSConfig) build(base *tls.Config) (*tls.Config, error) {
+	//nolint:exhaustruct // rest are defaults.
+	res := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+	if base != nil {
+		res = base.Clone()
+	}
+
+	if cfg.ServerName != "" {
+		res.ServerName = cfg.ServerName
+	}
+
+	//nolint:gosec // explicitly requested by config.
+	res.InsecureSkipVerify = cfg.InsecureSkipVerify
+
+	ca, err := readPEM(cfg.CA, cfg.CAFile)
+	if err != nil {
+		return nil, errorx.Wrap(err, "read ca")
+	}
+
+	if ca != nil {
+		res.RootCAs = x509.NewCertPool()
+		if !res.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, errorx.IllegalArgument.New("no certificates found in ca")
+		}
+	}
+
+	cert, err := readPEM(cfg.Cert, cfg.CertFile)
+	if err != nil {
+		return nil, errorx.Wrap(err, "read client certificate")
+	}
+
+	key, err := readPEM(cfg.Key, cfg.KeyFile)
+	if err != nil {
+		return nil, errorx.Wrap(err, "read client key")
+	}
+
+	if cert != nil || key != nil {
+		pair, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return nil, errorx.Wrap(err, "parse client key pair")
+		}
+
+		res.Certificates = []tls.Certificate{pair}
+	}
+
+	return res, nil
+}
+
+func readPEM(bs []byte, path string) ([]byte, error) {
+	if bs != nil || path == "" {
+		return bs, nil
+	}
+
+	res, err := os.ReadFile(path)
+
+	return res, errorx.Wrap(err, "read file %s", path)
+}
+
+func setIfNotZero[T comparable](dst *T, v T) {
+	var zero T
+	if v != zero {
+		*dst = v
+	}
+}
@@ -0,0 +1,34 @@
+package gredis_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sovamorco/gommon/gredis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFromConfig(t *testing.T) {
+	t.Parallel()
+
+	mr := miniredis.RunT(t)
+
+	//nolint:exhaustruct // rest are defaults.
+	cl, err := gredis.NewFromConfig(context.Background(), gredis.Config{
+		URL:         "redis://" + mr.Addr() + "?pool_size=3&read_timeout=1s",
+		PoolSize:    7,
+		PingTimeout: time.Second,
+	})
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = cl.Close() })
+
+	rc, ok := cl.(*redis.Client)
+	require.True(t, ok)
+	assert.Equal(t, 7, rc.Options().PoolSize)
+	assert.Equal(t, time.Second, rc.Options().ReadTimeout)
+}
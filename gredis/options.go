@@ -0,0 +1,130 @@
+package gredis
+
+import (
+	"crypto/tls"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type Option func(o *options)
+
+type options struct {
+	tracer        Tracer
+	slowThreshold time.Duration
+}
+
+// WithTracer creates a span for every command and pipeline.
+func WithTracer(t Tracer) Option {
+	return func(o *options) {
+		o.tracer = t
+	}
+}
+
+// WithSlowThreshold logs commands and pipelines that take longer than d with the logger from ctx.
+func WithSlowThreshold(d time.Duration) Option {
+	return func(o *options) {
+		o.slowThreshold = d
+	}
+}
+
+// applyOptions installs hooks requested by opts.
+func applyOptions(rsc redis.UniversalClient, opts []Option) {
+	var o options
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.tracer != nil || o.slowThreshold > 0 {
+		rsc.AddHook(tracingHook(o))
+	}
+}
+
+// clientOptions abstracts over options of different client types.
+type clientOptions interface {
+	client() redis.UniversalClient
+	common() commonOptions
+}
+
+// commonOptions points to fields shared by options of all client types.
+type commonOptions struct {
+	poolSize        *int
+	minIdleConns    *int
+	maxRetries      *int
+	dialTimeout     *time.Duration
+	readTimeout     *time.Duration
+	writeTimeout    *time.Duration
+	minRetryBackoff *time.Duration
+	maxRetryBackoff *time.Duration
+	tlsConfig       **tls.Config
+}
+
+type standaloneOptions struct {
+	*redis.Options
+}
+
+//nolint:ireturn // required by interface.
+func (o standaloneOptions) client() redis.UniversalClient {
+	return redis.NewClient(o.Options)
+}
+
+func (o standaloneOptions) common() commonOptions {
+	return commonOptions{
+		poolSize:        &o.PoolSize,
+		minIdleConns:    &o.MinIdleConns,
+		maxRetries:      &o.MaxRetries,
+		dialTimeout:     &o.DialTimeout,
+		readTimeout:     &o.ReadTimeout,
+		writeTimeout:    &o.WriteTimeout,
+		minRetryBackoff: &o.MinRetryBackoff,
+		maxRetryBackoff: &o.MaxRetryBackoff,
+		tlsConfig:       &o.TLSConfig,
+	}
+}
+
+type clusterOptions struct {
+	*redis.ClusterOptions
+}
+
+//nolint:ireturn // required by interface.
+func (o clusterOptions) client() redis.UniversalClient {
+	return redis.NewClusterClient(o.ClusterOptions)
+}
+
+func (o clusterOptions) common() commonOptions {
+	return commonOptions{
+		poolSize:        &o.PoolSize,
+		minIdleConns:    &o.MinIdleConns,
+		maxRetries:      &o.MaxRetries,
+		dialTimeout:     &o.DialTimeout,
+		readTimeout:     &o.ReadTimeout,
+		writeTimeout:    &o.WriteTimeout,
+		minRetryBackoff: &o.MinRetryBackoff,
+		maxRetryBackoff: &o.MaxRetryBackoff,
+		tlsConfig:       &o.TLSConfig,
+	}
+}
+
+type failoverOptions struct {
+	*redis.FailoverOptions
+}
+
+//nolint:ireturn // required by interface.
+func (o failoverOptions) client() redis.UniversalClient {
+	return redis.NewFailoverClient(o.FailoverOptions)
+}
+
+func (o failoverOptions) common() commonOptions {
+	return commonOptions{
+		poolSize:        &o.PoolSize,
+		minIdleConns:    &o.MinIdleConns,
+		maxRetries:      &o.MaxRetries,
+		dialTimeout:     &o.DialTimeout,
+		readTimeout:     &o.ReadTimeout,
+		writeTimeout:    &o.WriteTimeout,
+		minRetryBackoff: &o.MinRetryBackoff,
+		maxRetryBackoff: &o.MaxRetryBackoff,
+		tlsConfig:       &o.TLSConfig,
+	}
+}
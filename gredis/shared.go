@@ -0,0 +1,141 @@
+package gredis
+
+import (
+	"context"
+	"net/url"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sovamorco/errorx"
+)
+
+//nolint:gochecknoglobals // shared between all providers.
+var (
+	sharedMu sync.Mutex
+	shared   = make(map[string]*sharedEntry)
+)
+
+type sharedEntry struct {
+	// closed once client is created, entries that failed to create are removed before that.
+	ready  chan struct{}
+	client redis.UniversalClient
+	refs   int
+}
+
+// SharedClient is a reference to a client shared between everyone using the same url.
+// closing it releases the reference, the underlying client is closed with the last one.
+type SharedClient struct {
+	redis.UniversalClient
+
+	key  string
+	once sync.Once `exhaustruct:"optional"`
+}
+
+// NewShared returns a reference to the client for connurl, creating it if there is none yet.
+// urls are compared without the fragment, so providers with different prefixes share the client.
+// opts are only applied when the client is created.
+func NewShared(ctx context.Context, connurl string, opts ...Option) (*SharedClient, error) {
+	key, err := normalizeURL(connurl)
+	if err != nil {
+		return nil, errorx.Wrap(err, "normalize connection url")
+	}
+
+	for {
+		sharedMu.Lock()
+
+		e, ok := shared[key]
+		if !ok {
+			break
+		}
+
+		select {
+		case <-e.ready:
+			e.refs++
+			sharedMu.Unlock()
+
+			return &SharedClient{
+				UniversalClient: e.client,
+				key:             key,
+			}, nil
+		default:
+		}
+
+		sharedMu.Unlock()
+
+		// another caller is creating the client, the entry is gone if it failed and the loop creates it again.
+		select {
+		case <-e.ready:
+		case <-ctx.Done():
+			return nil, errorx.Wrap(ctx.Err(), "wait for redis client")
+		}
+	}
+
+	// the client is created without holding the lock, so that pinging it does not block other urls.
+	e := &sharedEntry{
+		ready:  make(chan struct{}),
+		client: nil,
+		refs:   1,
+	}
+	shared[key] = e
+
+	sharedMu.Unlock()
+
+	cl, err := New(ctx, connurl, opts...)
+
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+
+	if err != nil {
+		delete(shared, key)
+		close(e.ready)
+
+		return nil, errorx.Wrap(err, "create redis client")
+	}
+
+	e.client = cl
+	close(e.ready)
+
+	return &SharedClient{
+		UniversalClient: cl,
+		key:             key,
+	}, nil
+}
+
+// Close releases the reference, closing the underlying client if it was the last one.
+// closing the same reference more than once does nothing.
+func (c *SharedClient) Close() error {
+	var err error
+
+	c.once.Do(func() {
+		sharedMu.Lock()
+		defer sharedMu.Unlock()
+
+		e, ok := shared[c.key]
+		if !ok {
+			return
+		}
+
+		e.refs--
+		if e.refs > 0 {
+			return
+		}
+
+		delete(shared, c.key)
+
+		err = errorx.Wrap(e.client.Close(), "close redis client")
+	})
+
+	return err
+}
+
+func normalizeURL(connurl string) (string, error) {
+	u, err := url.Parse(connurl)
+	if err != nil {
+		return "", errorx.Wrap(err, "parse connection url")
+	}
+
+	u.Fragment = ""
+	u.RawFragment = ""
+
+	return u.String(), nil
+}
@@ -0,0 +1,92 @@
+package gredis_test
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/sovamorco/gommon/gredis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewShared(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+
+	first, err := gredis.NewShared(ctx, "redis://"+mr.Addr()+"#first")
+	require.NoError(t, err)
+
+	second, err := gredis.NewShared(ctx, "redis://"+mr.Addr()+"#second")
+	require.NoError(t, err)
+
+	assert.Same(t, first.UniversalClient, second.UniversalClient)
+
+	require.NoError(t, first.Close())
+	require.NoError(t, first.Close())
+	require.NoError(t, second.Ping(ctx).Err())
+
+	require.NoError(t, second.Close())
+	require.Error(t, second.Ping(ctx).Err())
+}
+
+func TestNewSharedConcurrent(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+
+	// accepts connections but never replies, so that pinging it hangs.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = ln.Close() })
+
+	hangCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	hangDone := make(chan error, 1)
+
+	go func() {
+		_, err := gredis.NewShared(hangCtx, "redis://"+ln.Addr().String())
+		hangDone <- err
+	}()
+
+	clients := make([]*gredis.SharedClient, 5)
+
+	var wg sync.WaitGroup
+
+	start := time.Now()
+
+	for i := range clients {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			cl, err := gredis.NewShared(ctx, "redis://"+mr.Addr())
+			assert.NoError(t, err)
+
+			clients[i] = cl
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+
+	for _, cl := range clients[1:] {
+		assert.Same(t, clients[0].UniversalClient, cl.UniversalClient)
+	}
+
+	for _, cl := range clients {
+		require.NoError(t, cl.Close())
+	}
+
+	require.Error(t, <-hangDone)
+}
@@ -0,0 +1,221 @@
+package gredis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+const (
+	AttrSystem    = "db.system"
+	AttrOperation = "db.operation"
+	AttrKeyPrefix = "db.redis.key_prefix"
+	AttrNumCmd    = "db.redis.num_cmd"
+)
+
+// Tracer creates spans for redis commands, so that any tracing library can be plugged in
+// with a small adapter.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+type Span interface {
+	SetAttribute(key, value string)
+	RecordError(err error)
+	End()
+}
+
+// tracingHook creates a span per command and pipeline and logs commands slower than slowThreshold.
+type tracingHook struct {
+	tracer        Tracer
+	slowThreshold time.Duration
+}
+
+func (h tracingHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (h tracingHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+
+		ctx, span := h.start(ctx, "redis."+cmd.Name())
+		if span != nil {
+			span.SetAttribute(AttrOperation, cmd.Name())
+
+			if prefix := keyPrefix(cmd); prefix != "" {
+				span.SetAttribute(AttrKeyPrefix, prefix)
+			}
+		}
+
+		err := next(ctx, cmd)
+
+		h.finish(ctx, span, err, start, cmd.Name())
+
+		return err
+	}
+}
+
+func (h tracingHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+
+		names := make([]string, len(cmds))
+		for i, cmd := range cmds {
+			names[i] = cmd.Name()
+		}
+
+		operation := strings.Join(names, " ")
+
+		ctx, span := h.start(ctx, "redis.pipeline")
+		if span != nil {
+			span.SetAttribute(AttrOperation, operation)
+			span.SetAttribute(AttrNumCmd, fmt.Sprint(len(cmds)))
+		}
+
+		err := next(ctx, cmds)
+
+		h.finish(ctx, span, err, start, operation)
+
+		return err
+	}
+}
+
+func (h tracingHook) start(ctx context.Context, name string) (context.Context, Span) {
+	if h.tracer == nil {
+		return ctx, nil
+	}
+
+	ctx, span := h.tracer.Start(ctx, name)
+	span.SetAttribute(AttrSystem, "redis")
+
+	return ctx, span
+}
+
+func (h tracingHook) finish(ctx context.Context, span Span, err error, start time.Time, operation string) {
+	// missing keys are not errors for the caller.
+	if errors.Is(err, redis.Nil) {
+		err = nil
+	}
+
+	if span != nil {
+		if err != nil {
+			span.RecordError(err)
+		}
+
+		span.End()
+	}
+
+	elapsed := time.Since(start)
+	if h.slowThreshold > 0 && elapsed >= h.slowThreshold {
+		zerolog.Ctx(ctx).Warn().Err(err).Str("operation", operation).Dur("elapsed", elapsed).
+			Msg("Slow redis command")
+	}
+}
+
+// keyPrefix returns the part of the first key of cmd before the first colon.
+func keyPrefix(cmd redis.Cmder) string {
+	args := cmd.Args()
+
+	pos := 1
+
+	switch cmd.Name() {
+	case "eval", "evalsha", "eval_ro", "evalsha_ro":
+		if len(args) < 4 || fmt.Sprint(args[2]) == "0" {
+			return ""
+		}
+
+		pos = 3
+	case "ping", "info", "hello", "client", "cluster", "select", "auth", "script", "time":
+		return ""
+	}
+
+	if len(args) <= pos {
+		return ""
+	}
+
+	key, ok := args[pos].(string)
+	if !ok {
+		return ""
+	}
+
+	prefix, _, _ := strings.Cut(key, ":")
+
+	return prefix
+}
+
+// RecordingTracer keeps finished spans in memory, useful in tests.
+type RecordingTracer struct {
+	spans []RecordedSpan
+	mu    sync.Mutex `exhaustruct:"optional"`
+}
+
+type RecordedSpan struct {
+	Name       string
+	Attributes map[string]string
+	Err        error
+	Start      time.Time
+	End        time.Time
+}
+
+func NewRecordingTracer() *RecordingTracer {
+	return &RecordingTracer{
+		spans: nil,
+	}
+}
+
+//nolint:ireturn // required by interface.
+func (t *RecordingTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	return ctx, &recordingSpan{
+		tracer: t,
+		span: RecordedSpan{
+			Name:       name,
+			Attributes: make(map[string]string),
+			Err:        nil,
+			Start:      time.Now(),
+			End:        time.Time{},
+		},
+	}
+}
+
+// Spans returns spans that have ended.
+func (t *RecordingTracer) Spans() []RecordedSpan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	res := make([]RecordedSpan, len(t.spans))
+	copy(res, t.spans)
+
+	return res
+}
+
+type recordingSpan struct {
+	tracer *RecordingTracer
+	span   RecordedSpan
+}
+
+func (s *recordingSpan) SetAttribute(key, value string) {
+	s.span.Attributes[key] = value
+}
+
+func (s *recordingSpan) RecordError(err error) {
+	s.span.Err = err
+}
+
+func (s *recordingSpan) End() {
+	s.span.End = time.Now()
+
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+
+	s.tracer.spans = append(s.tracer.spans, s.span)
+}
@@ -0,0 +1,47 @@
+package gredis_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sovamorco/gommon/gredis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracing(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	tracer := gredis.NewRecordingTracer()
+
+	cl, err := gredis.NewClient("redis://"+mr.Addr(), gredis.WithTracer(tracer))
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = cl.Close() })
+
+	require.NoError(t, cl.Set(ctx, "users:42", "value", 0).Err())
+	require.ErrorIs(t, cl.Get(ctx, "users:missing").Err(), redis.Nil)
+	require.Error(t, cl.Incr(ctx, "users:42").Err())
+
+	_, err = cl.Pipelined(ctx, func(p redis.Pipeliner) error {
+		p.Get(ctx, "users:42")
+		p.Del(ctx, "users:42")
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	spans := tracer.Spans()
+	require.Len(t, spans, 4)
+
+	assert.Equal(t, "redis.set", spans[0].Name)
+	assert.Equal(t, "users", spans[0].Attributes[gredis.AttrKeyPrefix])
+	require.NoError(t, spans[1].Err)
+	require.Error(t, spans[2].Err)
+	assert.Equal(t, "redis.pipeline", spans[3].Name)
+	assert.Equal(t, "get del", spans[3].Attributes[gredis.AttrOperation])
+}
@@ -0,0 +1,64 @@
+package health
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/redis/go-redis/v9"
+	"github.com/sovamorco/errorx"
+	"github.com/sovamorco/gommon/broker"
+	"github.com/sovamorco/gommon/storage"
+)
+
+var ErrUnsupported = errors.New("component does not support health checks")
+
+// Pinger is implemented by components that can check their connection.
+// it is not part of broker.Broker and storage.Storage, so that other implementations of them keep working.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Redis checks clients created by gredis.
+//
+//nolint:ireturn // checker implementations are interchangeable.
+func Redis(cl redis.UniversalClient) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		return errorx.Wrap(cl.Ping(ctx).Err(), "ping redis")
+	})
+}
+
+// DB checks databases created by gsqlx.
+//
+//nolint:ireturn // checker implementations are interchangeable.
+func DB(db *sqlx.DB) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		return errorx.Wrap(db.PingContext(ctx), "ping database")
+	})
+}
+
+// Storage checks storages implementing Pinger, like the ones of this module.
+//
+//nolint:ireturn // checker implementations are interchangeable.
+func Storage(s storage.Storage) (Checker, error) {
+	return pinger(s, "storage")
+}
+
+// Broker checks brokers implementing Pinger, like the ones of this module.
+//
+//nolint:ireturn // checker implementations are interchangeable.
+func Broker(b broker.Broker) (Checker, error) {
+	return pinger(b, "broker")
+}
+
+//nolint:ireturn // checker implementations are interchangeable.
+func pinger(v any, what string) (Checker, error) {
+	p, ok := v.(Pinger)
+	if !ok {
+		return nil, errorx.Wrap(ErrUnsupported, "check %s", what)
+	}
+
+	return CheckerFunc(func(ctx context.Context) error {
+		return errorx.Wrap(p.Ping(ctx), "ping %s", what)
+	}), nil
+}
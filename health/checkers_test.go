@@ -0,0 +1,64 @@
+package health_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/sovamorco/gommon/broker"
+	_ "github.com/sovamorco/gommon/broker/mock"
+	"github.com/sovamorco/gommon/gredis"
+	"github.com/sovamorco/gommon/health"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func TestRedis(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+
+	cl, err := gredis.NewClient("redis://" + mr.Addr())
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = cl.Close() })
+
+	c := health.Redis(cl)
+	require.NoError(t, c.Check(ctx))
+
+	mr.Close()
+	require.Error(t, c.Check(ctx))
+}
+
+func TestDB(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	db, err := sqlx.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+
+	c := health.DB(db)
+	require.NoError(t, c.Check(ctx))
+
+	require.NoError(t, db.Close())
+	require.Error(t, c.Check(ctx))
+}
+
+func TestBroker(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	b, err := broker.New(ctx, broker.Config{Provider: "mock", URL: ""})
+	require.NoError(t, err)
+
+	c, err := health.Broker(b)
+	require.NoError(t, err)
+	require.NoError(t, c.Check(ctx))
+
+	_, err = health.Broker(struct{ broker.Broker }{b})
+	require.ErrorIs(t, err, health.ErrUnsupported)
+}
@@ -0,0 +1,182 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const DefaultTimeout = 5 * time.Second
+
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+type CheckerFunc func(ctx context.Context) error
+
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+type Status string
+
+const (
+	StatusOK   Status = "ok"
+	StatusFail Status = "fail"
+)
+
+type Report struct {
+	Status     Status                     `json:"status"`
+	Components map[string]ComponentReport `json:"components"`
+}
+
+type ComponentReport struct {
+	Status    Status  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Health aggregates checkers into liveness and readiness probes.
+// liveness should only include checks that require a restart to recover from,
+// readiness includes every registered checker.
+type Health struct {
+	timeout  time.Duration
+	liveness map[string]Checker
+	all      map[string]Checker
+	mu       sync.RWMutex `exhaustruct:"optional"`
+}
+
+// New creates probes that fail checks running longer than timeout, DefaultTimeout if zero.
+func New(timeout time.Duration) *Health {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	return &Health{
+		timeout:  timeout,
+		liveness: make(map[string]Checker),
+		all:      make(map[string]Checker),
+	}
+}
+
+// AddReadiness registers checker used only by readiness probe.
+func (h *Health) AddReadiness(name string, c Checker) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.all[name] = c
+}
+
+// AddLiveness registers checker used by both liveness and readiness probes.
+func (h *Health) AddLiveness(name string, c Checker) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.liveness[name] = c
+	h.all[name] = c
+}
+
+func (h *Health) Liveness(ctx context.Context) Report {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.run(ctx, h.liveness)
+}
+
+func (h *Health) Readiness(ctx context.Context) Report {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.run(ctx, h.all)
+}
+
+func (h *Health) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, r, h.Liveness(r.Context()))
+	})
+}
+
+func (h *Health) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, r, h.Readiness(r.Context()))
+	})
+}
+
+// Register adds liveness and readiness handlers to mux at /livez and /readyz.
+func (h *Health) Register(mux *http.ServeMux) {
+	mux.Handle("/livez", h.LivenessHandler())
+	mux.Handle("/readyz", h.ReadinessHandler())
+}
+
+func (h *Health) run(ctx context.Context, checkers map[string]Checker) Report {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	res := Report{
+		Status:     StatusOK,
+		Components: make(map[string]ComponentReport, len(checkers)),
+	}
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+
+	for name, c := range checkers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			cr := check(ctx, c)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			res.Components[name] = cr
+			if cr.Status != StatusOK {
+				res.Status = StatusFail
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return res
+}
+
+func check(ctx context.Context, c Checker) ComponentReport {
+	start := time.Now()
+
+	err := c.Check(ctx)
+
+	res := ComponentReport{
+		Status:    StatusOK,
+		LatencyMS: float64(time.Since(start).Microseconds()) / float64(time.Millisecond/time.Microsecond),
+		Error:     "",
+	}
+
+	if err != nil {
+		res.Status = StatusFail
+		res.Error = err.Error()
+	}
+
+	return res
+}
+
+func writeReport(w http.ResponseWriter, r *http.Request, rep Report) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if rep.Status != StatusOK {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	err := json.NewEncoder(w).Encode(rep)
+	if err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("Failed to write health report")
+	}
+}
@@ -0,0 +1,40 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sovamorco/gommon/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlers(t *testing.T) {
+	t.Parallel()
+
+	h := health.New(0)
+	h.AddLiveness("ok", health.CheckerFunc(func(context.Context) error { return nil }))
+	h.AddReadiness("failing", health.CheckerFunc(func(context.Context) error { return errors.New("down") }))
+
+	mux := http.NewServeMux()
+	h.Register(mux)
+
+	live := httptest.NewRecorder()
+	mux.ServeHTTP(live, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	assert.Equal(t, http.StatusOK, live.Code)
+
+	ready := httptest.NewRecorder()
+	mux.ServeHTTP(ready, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, ready.Code)
+
+	var rep health.Report
+
+	require.NoError(t, json.Unmarshal(ready.Body.Bytes(), &rep))
+	assert.Equal(t, health.StatusFail, rep.Status)
+	assert.Equal(t, health.StatusOK, rep.Components["ok"].Status)
+	assert.Equal(t, "down", rep.Components["failing"].Error)
+}
@@ -0,0 +1,207 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/sovamorco/gommon/locker"
+)
+
+const DefaultRetryInterval = 5 * time.Second
+
+// Elector campaigns for leadership by holding a lock named name for as long as possible.
+// one elector should only campaign once at a time.
+type Elector struct {
+	locker   locker.Locker
+	name     string
+	interval time.Duration
+	lockOpts []locker.LockOption
+
+	onElected []func(ctx context.Context)
+	onRevoked []func()
+
+	leader   atomic.Bool `exhaustruct:"optional"`
+	watchers []chan bool
+	mu       sync.Mutex `exhaustruct:"optional"`
+}
+
+type Option func(e *Elector)
+
+// WithRetryInterval sets average delay between campaign attempts, DefaultRetryInterval by default.
+// actual delay is randomized between half and one and a half of it.
+func WithRetryInterval(d time.Duration) Option {
+	return func(e *Elector) {
+		if d > 0 {
+			e.interval = d
+		}
+	}
+}
+
+// WithLockOptions sets options passed to every Lock call.
+func WithLockOptions(opts ...locker.LockOption) Option {
+	return func(e *Elector) {
+		e.lockOpts = append(e.lockOpts, opts...)
+	}
+}
+
+// OnElected adds callback called with context of the term when leadership is gained.
+// callbacks are called synchronously and should not block.
+func OnElected(f func(ctx context.Context)) Option {
+	return func(e *Elector) {
+		e.onElected = append(e.onElected, f)
+	}
+}
+
+// OnRevoked adds callback called when leadership is lost.
+// callbacks are called synchronously and should not block.
+func OnRevoked(f func()) Option {
+	return func(e *Elector) {
+		e.onRevoked = append(e.onRevoked, f)
+	}
+}
+
+func New(l locker.Locker, name string, opts ...Option) *Elector {
+	e := &Elector{
+		locker:    l,
+		name:      name,
+		interval:  DefaultRetryInterval,
+		lockOpts:  nil,
+		onElected: nil,
+		onRevoked: nil,
+		watchers:  nil,
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
+}
+
+func (e *Elector) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// Watch returns channel that receives leadership status after each change.
+// only the latest status is kept if the channel is not read in time.
+func (e *Elector) Watch() <-chan bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	ch := make(chan bool, 1)
+	e.watchers = append(e.watchers, ch)
+
+	return ch
+}
+
+// Campaign keeps trying to become the leader until ctx is done.
+func (e *Elector) Campaign(ctx context.Context) {
+	e.Run(ctx, func(leaderCtx context.Context) error {
+		<-leaderCtx.Done()
+
+		return nil
+	})
+}
+
+// Run campaigns until ctx is done and calls f every time leadership is gained.
+// ctx passed to f is cancelled when leadership is lost, and the lock is held until f returns,
+// so that no two replicas run f at the same time. leadership is given up when f returns.
+func (e *Elector) Run(ctx context.Context, f func(leaderCtx context.Context) error) {
+	logger := zerolog.Ctx(ctx).With().Str("election", e.name).Logger()
+
+	for {
+		lk, err := e.locker.Lock(ctx, e.name, e.lockOpts...)
+
+		switch {
+		case err == nil:
+			e.term(ctx, lk, f)
+		case errors.Is(err, locker.ErrLocked), ctx.Err() != nil:
+		default:
+			logger.Error().Err(err).Msg("Failed to campaign for leadership")
+		}
+
+		t := time.NewTimer(e.jitter())
+
+		select {
+		case <-ctx.Done():
+			t.Stop()
+
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func (e *Elector) term(ctx context.Context, lk locker.Lock, f func(leaderCtx context.Context) error) {
+	logger := zerolog.Ctx(ctx).With().Str("election", e.name).Logger()
+
+	leaderCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	e.setLeader(true)
+
+	for _, cb := range e.onElected {
+		cb(leaderCtx)
+	}
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- f(leaderCtx)
+	}()
+
+	var err error
+
+	select {
+	case <-lk.Lost():
+		logger.Warn().Msg("Leadership lost")
+
+		cancel()
+		err = <-done
+	case err = <-done:
+	}
+
+	cancel()
+
+	e.setLeader(false)
+
+	for _, cb := range e.onRevoked {
+		cb()
+	}
+
+	if err != nil && !errors.Is(err, context.Canceled) {
+		logger.Error().Err(err).Msg("Leader function failed")
+	}
+
+	err = locker.UnlockDetached(ctx, lk)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to release leadership lock")
+	}
+}
+
+func (e *Elector) setLeader(leader bool) {
+	e.leader.Store(leader)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, ch := range e.watchers {
+		// drop the previous status if it was not read yet.
+		select {
+		case <-ch:
+		default:
+		}
+
+		ch <- leader
+	}
+}
+
+func (e *Elector) jitter() time.Duration {
+	//nolint:gosec // no need for secure randomness.
+	return e.interval/2 + rand.N(e.interval)
+}
@@ -0,0 +1,71 @@
+package leader_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sovamorco/gommon/leader"
+	"github.com/sovamorco/gommon/locker"
+	_ "github.com/sovamorco/gommon/locker/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestElector(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	l, err := locker.New(ctx, locker.Config{Provider: "memory", URL: ""})
+	require.NoError(t, err)
+
+	firstCtx, cancelFirst := context.WithCancel(ctx)
+	defer cancelFirst()
+
+	first := leader.New(l, "leader", leader.WithRetryInterval(10*time.Millisecond))
+	firstStatus := first.Watch()
+
+	go first.Campaign(firstCtx)
+
+	require.True(t, <-firstStatus)
+
+	var revoked atomic.Bool
+
+	second := leader.New(l, "leader", leader.WithRetryInterval(10*time.Millisecond),
+		leader.OnRevoked(func() { revoked.Store(true) }))
+
+	secondCtx, cancelSecond := context.WithCancel(ctx)
+	defer cancelSecond()
+
+	runs := make(chan struct{}, 1)
+
+	go second.Run(secondCtx, func(leaderCtx context.Context) error {
+		runs <- struct{}{}
+
+		<-leaderCtx.Done()
+
+		return nil
+	})
+
+	time.Sleep(50 * time.Millisecond)
+	assert.False(t, second.IsLeader())
+
+	cancelFirst()
+	require.False(t, <-firstStatus)
+
+	select {
+	case <-runs:
+	case <-time.After(time.Second):
+		t.Fatal("second elector did not become the leader")
+	}
+
+	assert.True(t, second.IsLeader())
+	assert.False(t, first.IsLeader())
+
+	cancelSecond()
+
+	assert.Eventually(t, revoked.Load, time.Second, 10*time.Millisecond)
+	assert.False(t, second.IsLeader())
+}
@@ -0,0 +1,164 @@
+package locker
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/sovamorco/gommon/metrics"
+)
+
+const (
+	MetricAcquireDuration = "locker_acquire_duration_seconds"
+	MetricContended       = "locker_contended_total"
+	MetricErrors          = "locker_errors_total"
+	MetricHoldDuration    = "locker_hold_duration_seconds"
+	MetricLost            = "locker_lost_total"
+	MetricExtendFailures  = "locker_extend_failures_total"
+)
+
+// PrefixPattern groups lock names by the part before the first colon.
+func PrefixPattern(name string) string {
+	prefix, _, _ := strings.Cut(name, ":")
+
+	return prefix
+}
+
+// Instrumented records acquisition latency, contention, errors, hold duration and losses of locks.
+// failed extensions are counted separately for locks implementing ExtendNotifier, including retried ones.
+// all metrics are labeled with pattern of the lock name, errors additionally with operation.
+type Instrumented struct {
+	l             Locker
+	m             metrics.Metrics
+	pattern       func(name string) string
+	holdThreshold time.Duration
+}
+
+type InstrumentOption func(i *Instrumented)
+
+// WithPattern sets function mapping lock names to pattern label, PrefixPattern by default.
+// patterns should not include ids, so that each lock does not create its own series.
+func WithPattern(f func(name string) string) InstrumentOption {
+	return func(i *Instrumented) {
+		i.pattern = f
+	}
+}
+
+// WithHoldThreshold logs a warning for locks held longer than d, disabled by default.
+func WithHoldThreshold(d time.Duration) InstrumentOption {
+	return func(i *Instrumented) {
+		i.holdThreshold = d
+	}
+}
+
+func Instrument(l Locker, m metrics.Metrics, opts ...InstrumentOption) *Instrumented {
+	i := &Instrumented{
+		l:             l,
+		m:             m,
+		pattern:       PrefixPattern,
+		holdThreshold: 0,
+	}
+
+	for _, opt := range opts {
+		opt(i)
+	}
+
+	return i
+}
+
+//nolint:ireturn // required by interface.
+func (i *Instrumented) Lock(ctx context.Context, name string, opts ...LockOption) (Lock, error) {
+	pattern := i.pattern(name)
+	start := time.Now()
+
+	lk, err := i.l.Lock(ctx, name, opts...)
+
+	i.m.Observe(MetricAcquireDuration, i.labels(pattern), time.Since(start).Seconds())
+
+	switch {
+	case errors.Is(err, ErrLocked):
+		i.m.Inc(MetricContended, i.labels(pattern))
+
+		return nil, err
+	case err != nil:
+		i.m.Inc(MetricErrors, i.opLabels(pattern, "lock"))
+
+		return nil, err
+	}
+
+	il := &instrumentedLock{
+		Lock:     lk,
+		parent:   i,
+		pattern:  pattern,
+		acquired: time.Now(),
+		warning:  nil,
+		stop:     make(chan struct{}),
+	}
+
+	if i.holdThreshold > 0 {
+		logger := zerolog.Ctx(ctx)
+
+		il.warning = time.AfterFunc(i.holdThreshold, func() {
+			logger.Warn().Str("lock", name).Dur("threshold", i.holdThreshold).Msg("Lock held longer than threshold")
+		})
+	}
+
+	if n, ok := lk.(ExtendNotifier); ok {
+		n.OnExtendFailure(func(error) {
+			i.m.Inc(MetricExtendFailures, i.labels(pattern))
+		})
+	}
+
+	go il.watch()
+
+	return il, nil
+}
+
+func (i *Instrumented) labels(pattern string) metrics.Labels {
+	return metrics.Labels{"pattern": pattern}
+}
+
+func (i *Instrumented) opLabels(pattern, op string) metrics.Labels {
+	return metrics.Labels{"pattern": pattern, "operation": op}
+}
+
+type instrumentedLock struct {
+	Lock
+
+	parent   *Instrumented
+	pattern  string
+	acquired time.Time
+	warning  *time.Timer
+	stop     chan struct{}
+	stopOnce sync.Once `exhaustruct:"optional"`
+}
+
+func (il *instrumentedLock) Unlock(ctx context.Context) error {
+	il.stopOnce.Do(func() {
+		close(il.stop)
+
+		if il.warning != nil {
+			il.warning.Stop()
+		}
+
+		il.parent.m.Observe(MetricHoldDuration, il.parent.labels(il.pattern), time.Since(il.acquired).Seconds())
+	})
+
+	err := il.Lock.Unlock(ctx)
+	if err != nil {
+		il.parent.m.Inc(MetricErrors, il.parent.opLabels(il.pattern, "unlock"))
+	}
+
+	return err
+}
+
+func (il *instrumentedLock) watch() {
+	select {
+	case <-il.stop:
+	case <-il.Lost():
+		il.parent.m.Inc(MetricLost, il.parent.labels(il.pattern))
+	}
+}
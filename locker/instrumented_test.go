@@ -0,0 +1,45 @@
+package locker_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sovamorco/gommon/locker"
+	"github.com/sovamorco/gommon/locker/memory"
+	"github.com/sovamorco/gommon/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstrument(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	m := metrics.NewMemory(nil)
+	l := locker.Instrument(memory.New(0), m, locker.WithHoldThreshold(time.Millisecond))
+
+	labels := metrics.Labels{"pattern": "account"}
+
+	held, err := l.Lock(ctx, "account:1")
+	require.NoError(t, err)
+
+	_, err = l.Lock(ctx, "account:1")
+	require.ErrorIs(t, err, locker.ErrLocked)
+
+	require.NoError(t, held.Unlock(ctx))
+	require.Error(t, held.Unlock(ctx))
+
+	expiring, err := l.Lock(ctx, "account:2", locker.WithExpiry(10*time.Millisecond))
+	require.NoError(t, err)
+
+	<-expiring.Lost()
+
+	assert.EqualValues(t, 3, m.Histogram(locker.MetricAcquireDuration, labels).Count)
+	assert.InDelta(t, 1, m.Counter(locker.MetricContended, labels), 0)
+	assert.EqualValues(t, 1, m.Histogram(locker.MetricHoldDuration, labels).Count)
+	assert.InDelta(t, 1, m.Counter(locker.MetricErrors, metrics.Labels{"pattern": "account", "operation": "unlock"}), 0)
+	assert.Eventually(t, func() bool {
+		return m.Counter(locker.MetricLost, labels) == 1
+	}, time.Second, time.Millisecond)
+}
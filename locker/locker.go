@@ -2,16 +2,37 @@ package locker
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/rs/zerolog"
+	"github.com/sovamorco/errorx"
 )
 
+var ErrLost = errors.New("lock lost")
+
+// UnlockTimeout bounds unlocking in UnlockDetached.
+const UnlockTimeout = 10 * time.Second
+
 type Locker interface {
-	Lock(ctx context.Context, name string) (Lock, error)
+	// Lock fails with ErrLocked if the lock is held, unless WithWait is passed.
+	Lock(ctx context.Context, name string, opts ...LockOption) (Lock, error)
 }
 
 type Lock interface {
 	Unlock(ctx context.Context) error
+	// Lost is closed once the lock can no longer be guaranteed to be held,
+	// work protected by the lock should be aborted then.
+	Lost() <-chan struct{}
+	// Token is a fencing token, greater than tokens of all previous acquisitions of the same name.
+	// downstream writes can reject tokens lower than the last seen one to protect from stale holders.
+	Token() uint64
+}
+
+// ExtendNotifier is implemented by locks that are extended in background.
+type ExtendNotifier interface {
+	// OnExtendFailure sets f called on every failed extension, including the ones that are retried.
+	OnExtendFailure(f func(err error))
 }
 
 // useful for defers.
@@ -23,3 +44,56 @@ func UnlockLog(ctx context.Context, l Lock) {
 		logger.Error().Err(err).Msg("Failed to unlock lock")
 	}
 }
+
+// UnlockDetached unlocks l with values of ctx, but without its cancellation, bounded by UnlockTimeout.
+// ctx may be done already, but the lock should still be released.
+func UnlockDetached(ctx context.Context, l Lock) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), UnlockTimeout)
+	defer cancel()
+
+	return l.Unlock(ctx)
+}
+
+// WithLock runs f while holding the lock for name.
+// ctx passed to f is cancelled with ErrLost as the cause if the lock is lost.
+// the lock is released even if f panics, unlock error is joined with the one returned by f.
+func WithLock(ctx context.Context, l Locker, name string, f func(ctx context.Context) error, opts ...LockOption) error {
+	lk, err := l.Lock(ctx, name, opts...)
+	if err != nil {
+		return errorx.Wrap(err, "lock %s", name)
+	}
+
+	lockCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	go func() {
+		select {
+		case <-lockCtx.Done():
+		case <-lk.Lost():
+			cancel(ErrLost)
+		}
+	}()
+
+	completed := false
+
+	defer func() {
+		if completed {
+			return
+		}
+
+		uerr := UnlockDetached(ctx, lk)
+		if uerr != nil {
+			zerolog.Ctx(ctx).Error().Err(uerr).Str("name", name).Msg("Failed to unlock lock")
+		}
+	}()
+
+	err = f(lockCtx)
+	completed = true
+
+	uerr := UnlockDetached(ctx, lk)
+	if uerr != nil {
+		uerr = errorx.Wrap(uerr, "unlock %s", name)
+	}
+
+	return errors.Join(err, uerr)
+}
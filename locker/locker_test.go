@@ -0,0 +1,44 @@
+package locker_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sovamorco/gommon/locker"
+	"github.com/sovamorco/gommon/locker/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithLock(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	m := memory.New(0)
+
+	errTest := errors.New("test")
+
+	err := locker.WithLock(ctx, m, "lock", func(context.Context) error {
+		assert.Len(t, m.Held(), 1)
+
+		return errTest
+	})
+	require.ErrorIs(t, err, errTest)
+	assert.Empty(t, m.Held())
+
+	assert.Panics(t, func() {
+		_ = locker.WithLock(ctx, m, "lock", func(context.Context) error {
+			panic("test")
+		})
+	})
+	assert.Empty(t, m.Held())
+
+	err = locker.WithLock(ctx, m, "lock", func(ctx context.Context) error {
+		<-ctx.Done()
+
+		return context.Cause(ctx)
+	}, locker.WithExpiry(20*time.Millisecond))
+	require.ErrorIs(t, err, locker.ErrLost)
+}
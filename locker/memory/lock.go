@@ -0,0 +1,221 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/sovamorco/errorx"
+	"github.com/sovamorco/gommon/locker"
+)
+
+type entry struct {
+	holder  *Lock
+	waiters []*waiter
+}
+
+type waiter struct {
+	owner  string
+	expiry time.Duration
+	// lock is set before ready is closed.
+	lock  *Lock
+	ready chan struct{}
+}
+
+// Lock waits in FIFO order if WithWait is passed, RetryDelay is ignored.
+// acquisitions without waiting fail while there are waiters, so that they cannot jump the queue.
+//
+//nolint:ireturn // required by interface.
+func (m *Memory) Lock(ctx context.Context, name string, opts ...locker.LockOption) (locker.Lock, error) {
+	o := locker.NewLockOptions(opts...)
+	owner, _ := locker.OwnerFromContext(ctx)
+
+	expiry := m.ttl
+	if o.Expiry > 0 {
+		expiry = o.Expiry
+	}
+
+	m.mu.Lock()
+
+	e, ok := m.locks[name]
+	if !ok {
+		e = &entry{
+			holder:  nil,
+			waiters: nil,
+		}
+		m.locks[name] = e
+	}
+
+	if e.holder == nil && len(e.waiters) == 0 {
+		l := m.grant(name, e, owner, expiry)
+
+		m.mu.Unlock()
+
+		return l, nil
+	}
+
+	if !o.Wait {
+		m.mu.Unlock()
+
+		return nil, errorx.Wrap(locker.ErrLocked, "lock %s", name)
+	}
+
+	w := &waiter{
+		owner:  owner,
+		expiry: expiry,
+		lock:   nil,
+		ready:  make(chan struct{}),
+	}
+	e.waiters = append(e.waiters, w)
+
+	m.mu.Unlock()
+
+	return m.wait(ctx, name, w, o.Timeout)
+}
+
+//nolint:ireturn // same as Lock.
+func (m *Memory) wait(ctx context.Context, name string, w *waiter, timeout time.Duration) (locker.Lock, error) {
+	var deadline <-chan time.Time
+
+	if timeout > 0 {
+		t := time.NewTimer(timeout)
+		defer t.Stop()
+
+		deadline = t.C
+	}
+
+	var err error
+
+	select {
+	case <-w.ready:
+		return w.lock, nil
+	case <-ctx.Done():
+		err = errorx.Wrap(ctx.Err(), "wait for lock")
+	case <-deadline:
+		err = errorx.Wrap(locker.ErrLocked, "lock %s", name)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// the lock could have been granted while waiting for mu.
+	if w.lock != nil {
+		m.release(name, w.lock)
+
+		return nil, err
+	}
+
+	e := m.locks[name]
+	e.waiters = slices.DeleteFunc(e.waiters, func(other *waiter) bool {
+		return other == w
+	})
+
+	if e.holder == nil && len(e.waiters) == 0 {
+		delete(m.locks, name)
+	}
+
+	return nil, err
+}
+
+// grant makes a new holder of e, should be called with mu held.
+func (m *Memory) grant(name string, e *entry, owner string, expiry time.Duration) *Lock {
+	now := time.Now()
+
+	l := &Lock{
+		name:       name,
+		owner:      owner,
+		parent:     m,
+		token:      m.nextToken(),
+		acquiredAt: now,
+		expiresAt:  time.Time{},
+		timer:      nil,
+		lost:       make(chan struct{}),
+	}
+
+	if expiry > 0 {
+		l.expiresAt = now.Add(expiry)
+		l.timer = time.AfterFunc(expiry, l.expire)
+	}
+
+	e.holder = l
+
+	return l
+}
+
+// release passes the lock held by l to the next waiter, should be called with mu held.
+func (m *Memory) release(name string, l *Lock) bool {
+	e, ok := m.locks[name]
+	if !ok || e.holder != l {
+		return false
+	}
+
+	if l.timer != nil {
+		l.timer.Stop()
+	}
+
+	e.holder = nil
+
+	if len(e.waiters) == 0 {
+		delete(m.locks, name)
+
+		return true
+	}
+
+	w := e.waiters[0]
+	e.waiters = e.waiters[1:]
+
+	w.lock = m.grant(name, e, w.owner, w.expiry)
+	close(w.ready)
+
+	return true
+}
+
+type Lock struct {
+	name       string
+	owner      string
+	parent     *Memory
+	token      uint64
+	acquiredAt time.Time
+	expiresAt  time.Time
+	timer      *time.Timer
+	lost       chan struct{}
+	lostOnce   sync.Once `exhaustruct:"optional"`
+}
+
+func (l *Lock) Unlock(_ context.Context) error {
+	l.parent.mu.Lock()
+	defer l.parent.mu.Unlock()
+
+	if !l.parent.release(l.name, l) {
+		return errorx.IllegalState.New("lock %s is not held", l.name)
+	}
+
+	return nil
+}
+
+// Lost is closed when the lock expires before being unlocked.
+func (l *Lock) Lost() <-chan struct{} {
+	return l.lost
+}
+
+func (l *Lock) Token() uint64 {
+	return l.token
+}
+
+func (l *Lock) expire() {
+	l.parent.mu.Lock()
+	defer l.parent.mu.Unlock()
+
+	e, ok := l.parent.locks[l.name]
+	if !ok || e.holder != l {
+		return
+	}
+
+	// the holder has to be told before the next one is granted the lock.
+	l.lostOnce.Do(func() {
+		close(l.lost)
+	})
+
+	l.parent.release(l.name, l)
+}
@@ -2,7 +2,10 @@ package memory
 
 import (
 	"context"
+	"net/url"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/sovamorco/errorx"
 	"github.com/sovamorco/gommon/locker"
@@ -10,42 +13,107 @@ import (
 
 //nolint:gochecknoinits // driver pattern.
 func init() {
-	locker.Register("mock", newMock)
+	locker.Register("memory", newMemory)
+	// kept for configs written before the provider was renamed.
+	locker.Register("mock", newMemory)
 }
 
+// Memory is a locker for single-instance deployments and tests.
+// waiting acquisitions are granted in FIFO order, locks with ttl are released if not unlocked in time.
+// only plain locks acquired with Lock expire and are listed by Held,
+// reentrant and read-write locks ignore ttl and locker.WithExpiry and are held until unlocked.
 type Memory struct {
-	locks sync.Map `exhaustruct:"optional"`
+	ttl time.Duration
+
+	locks     map[string]*entry
+	reentrant map[string]*reentrantState
+	rw        map[string]*rwState
+	permits   map[string]int
+	// last fencing token, shared by all names, so that it does not have to be kept per name.
+	token uint64
+	mu    sync.Mutex `exhaustruct:"optional"`
 }
 
-//nolint:ireturn // required by locker.Register.
-func newMock(_ context.Context, _ string) (locker.Locker, error) {
-	return &Memory{}, nil
+// HeldLock describes a lock acquired with Memory.Lock.
+type HeldLock struct {
+	Name  string
+	Token uint64
+	// owner from ctx passed to Lock, see locker.WithOwner.
+	Holder     string
+	AcquiredAt time.Time
+	// zero if the lock does not expire.
+	ExpiresAt time.Time
+	Waiters   int
 }
 
-// required by interface.
+// connection url can set default ttl of locks, e.g.
 //
-//nolint:ireturn
-func (m *Memory) Lock(_ context.Context, name string) (locker.Lock, error) {
-	l := &Lock{
-		name:   name,
-		parent: m,
+//	memory://?ttl=1m
+//
+//nolint:ireturn // required by locker.Register.
+func newMemory(_ context.Context, connst string) (locker.Locker, error) {
+	u, err := url.Parse(connst)
+	if err != nil {
+		return nil, errorx.Wrap(err, "parse connection url")
 	}
 
-	_, exists := m.locks.LoadOrStore(name, l)
-	if exists {
-		return nil, errorx.IllegalState.New("lock %s already exists", name)
+	var ttl time.Duration
+
+	if v := u.Query().Get("ttl"); v != "" {
+		ttl, err = time.ParseDuration(v)
+		if err != nil {
+			return nil, errorx.Wrap(err, "parse ttl")
+		}
 	}
 
-	return l, nil
+	return New(ttl), nil
+}
+
+// New creates a locker with locks released after ttl unless overridden with locker.WithExpiry.
+// locks do not expire if ttl is zero. ttl only applies to plain locks, see Memory.
+func New(ttl time.Duration) *Memory {
+	return &Memory{
+		ttl:       ttl,
+		locks:     make(map[string]*entry),
+		reentrant: make(map[string]*reentrantState),
+		rw:        make(map[string]*rwState),
+		permits:   make(map[string]int),
+		token:     0,
+	}
 }
 
-type Lock struct {
-	name   string
-	parent *Memory
+// Held lists locks acquired with Lock that are currently held, sorted by name.
+func (m *Memory) Held() []HeldLock {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	res := make([]HeldLock, 0, len(m.locks))
+
+	for name, e := range m.locks {
+		if e.holder == nil {
+			continue
+		}
+
+		res = append(res, HeldLock{
+			Name:       name,
+			Token:      e.holder.token,
+			Holder:     e.holder.owner,
+			AcquiredAt: e.holder.acquiredAt,
+			ExpiresAt:  e.holder.expiresAt,
+			Waiters:    len(e.waiters),
+		})
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+
+	return res
 }
 
-func (l *Lock) Unlock(_ context.Context) error {
-	l.parent.locks.Delete(l.name)
+// nextToken should be called with mu held.
+func (m *Memory) nextToken() uint64 {
+	m.token++
 
-	return nil
+	return m.token
 }
@@ -0,0 +1,95 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sovamorco/gommon/locker"
+	"github.com/sovamorco/gommon/locker/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockFIFO(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	m := memory.New(0)
+
+	held, err := m.Lock(ctx, "lock")
+	require.NoError(t, err)
+
+	order := make(chan int, 2)
+
+	for i := range 2 {
+		go func() {
+			l, err := m.Lock(locker.WithOwner(ctx, "waiter"), "lock", locker.WithWait(0))
+			assert.NoError(t, err)
+
+			order <- i
+
+			assert.NoError(t, l.Unlock(ctx))
+		}()
+
+		// make sure the waiters are queued in order.
+		require.Eventually(t, func() bool {
+			return m.Held()[0].Waiters == i+1
+		}, time.Second, time.Millisecond)
+	}
+
+	_, err = m.Lock(ctx, "lock")
+	require.ErrorIs(t, err, locker.ErrLocked)
+
+	require.NoError(t, held.Unlock(ctx))
+
+	assert.Equal(t, 0, <-order)
+	assert.Equal(t, 1, <-order)
+	assert.Empty(t, m.Held())
+}
+
+func TestLockTTL(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	m := memory.New(time.Hour)
+
+	expired, err := m.Lock(locker.WithOwner(ctx, "owner"), "lock", locker.WithExpiry(50*time.Millisecond))
+	require.NoError(t, err)
+
+	held := m.Held()
+	require.Len(t, held, 1)
+	assert.Equal(t, "owner", held[0].Holder)
+	assert.False(t, held[0].ExpiresAt.IsZero())
+
+	l, err := m.Lock(ctx, "lock", locker.WithWait(time.Second))
+	require.NoError(t, err)
+
+	select {
+	case <-expired.Lost():
+	default:
+		t.Fatal("expired lock is not lost")
+	}
+
+	require.Error(t, expired.Unlock(ctx))
+	require.NoError(t, l.Unlock(ctx))
+}
+
+func TestLockCancel(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	m := memory.New(0)
+
+	held, err := m.Lock(ctx, "lock")
+	require.NoError(t, err)
+
+	cctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+
+	_, err = m.Lock(cctx, "lock", locker.WithWait(0))
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	require.NoError(t, held.Unlock(ctx))
+	assert.Empty(t, m.Held())
+}
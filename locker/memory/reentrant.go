@@ -0,0 +1,96 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/sovamorco/errorx"
+	"github.com/sovamorco/gommon/locker"
+)
+
+type reentrantState struct {
+	owner string
+	count int
+	token uint64
+}
+
+// required by interface.
+//
+//nolint:ireturn
+func (m *Memory) LockReentrant(ctx context.Context, name string, opts ...locker.LockOption) (locker.Lock, error) {
+	owner, ok := locker.OwnerFromContext(ctx)
+	if !ok {
+		return nil, locker.ErrNoOwner
+	}
+
+	return locker.Retry(ctx, locker.NewLockOptions(opts...), func() (locker.Lock, error) {
+		return m.tryLockReentrant(name, owner)
+	})
+}
+
+//nolint:ireturn // same as LockReentrant.
+func (m *Memory) tryLockReentrant(name, owner string) (locker.Lock, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	st, ok := m.reentrant[name]
+	if ok && st.owner != owner {
+		return nil, errorx.Wrap(locker.ErrLocked, "lock %s", name)
+	}
+
+	if !ok {
+		st = &reentrantState{
+			owner: owner,
+			count: 0,
+			token: m.nextToken(),
+		}
+		m.reentrant[name] = st
+	}
+
+	st.count++
+
+	return &ReentrantLock{
+		name:     name,
+		owner:    owner,
+		parent:   m,
+		token:    st.token,
+		lost:     make(chan struct{}),
+		unlocked: false,
+	}, nil
+}
+
+type ReentrantLock struct {
+	name     string
+	owner    string
+	parent   *Memory
+	token    uint64
+	lost     chan struct{}
+	unlocked bool
+}
+
+func (l *ReentrantLock) Unlock(_ context.Context) error {
+	l.parent.mu.Lock()
+	defer l.parent.mu.Unlock()
+
+	st, ok := l.parent.reentrant[l.name]
+	if l.unlocked || !ok || st.owner != l.owner {
+		return errorx.IllegalState.New("lock %s is not held by %s", l.name, l.owner)
+	}
+
+	l.unlocked = true
+
+	st.count--
+	if st.count <= 0 {
+		delete(l.parent.reentrant, l.name)
+	}
+
+	return nil
+}
+
+// Lost is never closed, since only plain locks expire, see New.
+func (l *ReentrantLock) Lost() <-chan struct{} {
+	return l.lost
+}
+
+func (l *ReentrantLock) Token() uint64 {
+	return l.token
+}
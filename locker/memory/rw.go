@@ -0,0 +1,120 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/sovamorco/errorx"
+	"github.com/sovamorco/gommon/locker"
+)
+
+type rwState struct {
+	readers int
+	writer  bool
+}
+
+type RWLocker struct {
+	parent *Memory
+}
+
+//nolint:ireturn // required by locker.RWProvider.
+func (m *Memory) RW() locker.RWLocker {
+	return &RWLocker{
+		parent: m,
+	}
+}
+
+// required by interface.
+//
+//nolint:ireturn
+func (rw *RWLocker) RLock(ctx context.Context, name string, opts ...locker.LockOption) (locker.Lock, error) {
+	return locker.Retry(ctx, locker.NewLockOptions(opts...), func() (locker.Lock, error) {
+		return rw.tryLock(name, false)
+	})
+}
+
+// required by interface.
+//
+//nolint:ireturn
+func (rw *RWLocker) Lock(ctx context.Context, name string, opts ...locker.LockOption) (locker.Lock, error) {
+	return locker.Retry(ctx, locker.NewLockOptions(opts...), func() (locker.Lock, error) {
+		return rw.tryLock(name, true)
+	})
+}
+
+//nolint:ireturn // same as Lock.
+func (rw *RWLocker) tryLock(name string, write bool) (locker.Lock, error) {
+	m := rw.parent
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	st, ok := m.rw[name]
+	if !ok {
+		st = &rwState{
+			readers: 0,
+			writer:  false,
+		}
+		m.rw[name] = st
+	}
+
+	if st.writer || (write && st.readers > 0) {
+		return nil, errorx.Wrap(locker.ErrLocked, "lock %s", name)
+	}
+
+	if write {
+		st.writer = true
+	} else {
+		st.readers++
+	}
+
+	return &RWLock{
+		name:     name,
+		write:    write,
+		parent:   m,
+		token:    m.nextToken(),
+		lost:     make(chan struct{}),
+		unlocked: false,
+	}, nil
+}
+
+type RWLock struct {
+	name     string
+	write    bool
+	parent   *Memory
+	token    uint64
+	lost     chan struct{}
+	unlocked bool
+}
+
+func (l *RWLock) Unlock(_ context.Context) error {
+	l.parent.mu.Lock()
+	defer l.parent.mu.Unlock()
+
+	st, ok := l.parent.rw[l.name]
+	if l.unlocked || !ok {
+		return errorx.IllegalState.New("lock %s is not held", l.name)
+	}
+
+	l.unlocked = true
+
+	if l.write {
+		st.writer = false
+	} else {
+		st.readers--
+	}
+
+	if !st.writer && st.readers <= 0 {
+		delete(l.parent.rw, l.name)
+	}
+
+	return nil
+}
+
+// Lost is never closed, since only plain locks expire, see New.
+func (l *RWLock) Lost() <-chan struct{} {
+	return l.lost
+}
+
+func (l *RWLock) Token() uint64 {
+	return l.token
+}
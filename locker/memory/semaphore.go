@@ -0,0 +1,52 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sovamorco/errorx"
+	"github.com/sovamorco/gommon/locker"
+)
+
+// required by interface.
+//
+//nolint:ireturn
+func (m *Memory) Acquire(_ context.Context, name string, limit int) (locker.Permit, error) {
+	if limit <= 0 {
+		return nil, errorx.IllegalArgument.New("semaphore limit must be positive, got %d", limit)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.permits[name] >= limit {
+		return nil, locker.ErrNoPermits
+	}
+
+	m.permits[name]++
+
+	return &Permit{
+		name:   name,
+		parent: m,
+	}, nil
+}
+
+type Permit struct {
+	name   string
+	parent *Memory
+	once   sync.Once `exhaustruct:"optional"`
+}
+
+func (p *Permit) Release(_ context.Context) error {
+	p.once.Do(func() {
+		p.parent.mu.Lock()
+		defer p.parent.mu.Unlock()
+
+		p.parent.permits[p.name]--
+		if p.parent.permits[p.name] <= 0 {
+			delete(p.parent.permits, p.name)
+		}
+	})
+
+	return nil
+}
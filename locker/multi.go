@@ -0,0 +1,132 @@
+package locker
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+
+	"github.com/sovamorco/errorx"
+)
+
+// LockAll acquires locks for all of names, see LockAllWith.
+//
+//nolint:ireturn // depends on locker.
+func LockAll(ctx context.Context, l Locker, names ...string) (Lock, error) {
+	return LockAllWith(ctx, l, names)
+}
+
+// LockAllWith acquires locks for all of names in sorted order, so that calls with overlapping names do not deadlock.
+// either all of the locks are acquired, or the acquired ones are unlocked and the errors are joined.
+// duplicate names are only locked once.
+//
+//nolint:ireturn // depends on locker.
+func LockAllWith(ctx context.Context, l Locker, names []string, opts ...LockOption) (Lock, error) {
+	sorted := slices.Clone(names)
+	slices.Sort(sorted)
+	sorted = slices.Compact(sorted)
+
+	ml := &MultiLock{
+		names: sorted,
+		locks: make([]Lock, 0, len(sorted)),
+		stop:  make(chan struct{}),
+		lost:  make(chan struct{}),
+	}
+
+	for _, name := range sorted {
+		lk, err := l.Lock(ctx, name, opts...)
+		if err != nil {
+			err = errorx.Wrap(err, "lock %s", name)
+
+			return nil, errors.Join(err, UnlockDetached(ctx, ml))
+		}
+
+		ml.locks = append(ml.locks, lk)
+	}
+
+	ml.watch()
+
+	return ml, nil
+}
+
+// MultiLock is a composite of locks acquired by LockAll.
+type MultiLock struct {
+	names    []string
+	locks    []Lock
+	stop     chan struct{}
+	lost     chan struct{}
+	stopOnce sync.Once `exhaustruct:"optional"`
+	lostOnce sync.Once `exhaustruct:"optional"`
+}
+
+// Unlock releases all of the locks in reverse order, joining their errors.
+// only the first call releases them, the following ones fail.
+func (ml *MultiLock) Unlock(ctx context.Context) error {
+	unlocked := true
+
+	ml.stopOnce.Do(func() {
+		unlocked = false
+
+		close(ml.stop)
+	})
+
+	if unlocked {
+		return errorx.IllegalState.New("locks are already unlocked")
+	}
+
+	return ml.unlock(ctx)
+}
+
+// Lost is closed when any of the locks is lost.
+func (ml *MultiLock) Lost() <-chan struct{} {
+	return ml.lost
+}
+
+// Token is the highest of the tokens, see Tokens for each of them.
+func (ml *MultiLock) Token() uint64 {
+	var res uint64
+
+	for _, lk := range ml.locks {
+		res = max(res, lk.Token())
+	}
+
+	return res
+}
+
+// Tokens returns fencing token of each lock by name.
+func (ml *MultiLock) Tokens() map[string]uint64 {
+	res := make(map[string]uint64, len(ml.locks))
+
+	for i, lk := range ml.locks {
+		res[ml.names[i]] = lk.Token()
+	}
+
+	return res
+}
+
+func (ml *MultiLock) unlock(ctx context.Context) error {
+	errs := make([]error, 0, len(ml.locks))
+
+	for i, lk := range slices.Backward(ml.locks) {
+		err := lk.Unlock(ctx)
+		if err != nil {
+			errs = append(errs, errorx.Wrap(err, "unlock %s", ml.names[i]))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (ml *MultiLock) watch() {
+	for _, lk := range ml.locks {
+		go func() {
+			select {
+			case <-ml.stop:
+			case <-lk.Lost():
+				ml.lostOnce.Do(func() {
+					close(ml.lost)
+				})
+			}
+		}()
+	}
+}
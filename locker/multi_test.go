@@ -0,0 +1,44 @@
+package locker_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sovamorco/gommon/locker"
+	"github.com/sovamorco/gommon/locker/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockAll(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	m := memory.New(0)
+
+	held, err := m.Lock(ctx, "c")
+	require.NoError(t, err)
+
+	_, err = locker.LockAll(ctx, m, "c", "a", "b")
+	require.ErrorIs(t, err, locker.ErrLocked)
+	assert.Len(t, m.Held(), 1, "partially acquired locks are not rolled back")
+
+	require.NoError(t, held.Unlock(ctx))
+
+	lk, err := locker.LockAll(ctx, m, "b", "a", "b")
+	require.NoError(t, err)
+
+	ml, ok := lk.(*locker.MultiLock)
+	require.True(t, ok)
+	assert.Len(t, ml.Tokens(), 2)
+
+	require.NoError(t, lk.Unlock(ctx))
+	assert.Empty(t, m.Held())
+
+	other, err := m.Lock(ctx, "a")
+	require.NoError(t, err)
+
+	require.Error(t, lk.Unlock(ctx))
+	assert.Len(t, m.Held(), 1, "second unlock released someone else's lock")
+	require.NoError(t, other.Unlock(ctx))
+}
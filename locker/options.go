@@ -0,0 +1,144 @@
+package locker
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sovamorco/errorx"
+)
+
+const DefaultRetryDelay = 100 * time.Millisecond
+
+// ErrLocked is returned when the lock is held by someone else,
+// either immediately or after waiting for it runs out.
+var ErrLocked = errors.New("lock is already held")
+
+// LockOptions are per-call settings, providers ignore the ones they do not support.
+type LockOptions struct {
+	// Wait makes Lock retry until the lock is acquired instead of failing immediately.
+	Wait bool
+	// how long to wait for, until ctx is done if zero.
+	Timeout    time.Duration
+	RetryDelay time.Duration
+
+	// zero values and nil keep provider settings.
+	Expiry       time.Duration
+	ExtendBuffer time.Duration
+	AutoExtend   *bool
+	// attempts the provider makes on every try and delay between them, before Wait retries the try.
+	Tries    int
+	TryDelay time.Duration
+}
+
+type LockOption func(o *LockOptions)
+
+// WithWait makes Lock wait for the lock for up to timeout, or until ctx is done if timeout is not positive.
+func WithWait(timeout time.Duration) LockOption {
+	return func(o *LockOptions) {
+		o.Wait = true
+		o.Timeout = max(timeout, 0)
+	}
+}
+
+// WithRetryDelay sets delay between attempts while waiting, DefaultRetryDelay by default.
+func WithRetryDelay(d time.Duration) LockOption {
+	return func(o *LockOptions) {
+		if d > 0 {
+			o.RetryDelay = d
+		}
+	}
+}
+
+// WithExpiry sets how long the lock is held for without being extended.
+func WithExpiry(d time.Duration) LockOption {
+	return func(o *LockOptions) {
+		o.Expiry = d
+	}
+}
+
+// WithExtendBuffer sets how long before expiry the lock is extended.
+func WithExtendBuffer(d time.Duration) LockOption {
+	return func(o *LockOptions) {
+		o.ExtendBuffer = d
+	}
+}
+
+// WithAutoExtend enables or disables extending the lock while it is held.
+// locks that are not extended are lost once they expire.
+func WithAutoExtend(enabled bool) LockOption {
+	return func(o *LockOptions) {
+		o.AutoExtend = &enabled
+	}
+}
+
+// WithTries sets how many attempts the provider makes on every try, with delay between them.
+// unlike WithWait it also applies to calls that do not wait, zero delay keeps the provider setting.
+func WithTries(n int, delay time.Duration) LockOption {
+	return func(o *LockOptions) {
+		o.Tries = n
+		o.TryDelay = delay
+	}
+}
+
+// NewLockOptions applies opts over defaults, for use by providers.
+func NewLockOptions(opts ...LockOption) LockOptions {
+	o := LockOptions{
+		Wait:       false,
+		Timeout:    0,
+		RetryDelay: DefaultRetryDelay,
+
+		Expiry:       0,
+		ExtendBuffer: 0,
+		AutoExtend:   nil,
+		Tries:        0,
+		TryDelay:     0,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// Retry calls try until it fails with something other than ErrLocked, or waiting as configured by o runs out.
+// try should use the original ctx, so that locks outlive the wait timeout.
+func Retry[T any](ctx context.Context, o LockOptions, try func() (T, error)) (T, error) {
+	res, err := try()
+	if !o.Wait || !errors.Is(err, ErrLocked) {
+		return res, err
+	}
+
+	var (
+		zero     T
+		deadline <-chan time.Time
+	)
+
+	if o.Timeout > 0 {
+		timeout := time.NewTimer(o.Timeout)
+		defer timeout.Stop()
+
+		deadline = timeout.C
+	}
+
+	delay := time.NewTimer(o.RetryDelay)
+	defer delay.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return zero, errorx.Wrap(ctx.Err(), "wait for lock")
+		case <-deadline:
+			return zero, err
+		case <-delay.C:
+		}
+
+		res, err = try()
+		if !errors.Is(err, ErrLocked) {
+			return res, err
+		}
+
+		delay.Reset(o.RetryDelay)
+	}
+}
@@ -0,0 +1,220 @@
+package postgres
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+	"github.com/sovamorco/errorx"
+	"github.com/sovamorco/gommon/gsqlx"
+	"github.com/sovamorco/gommon/locker"
+)
+
+//nolint:ireturn // same as Lock.
+func (l *Locker) lockAdvisory(ctx context.Context, name string, o locker.LockOptions) (locker.Lock, error) {
+	conn, err := l.db.Connx(ctx)
+	if err != nil {
+		return nil, errorx.Wrap(err, "get connection")
+	}
+
+	key := lockKey(name)
+
+	acquired, err := acquireAdvisory(ctx, conn, key, o)
+	if err != nil || !acquired {
+		discard(conn)
+
+		if err != nil {
+			return nil, errorx.Wrap(err, "acquire advisory lock")
+		}
+
+		return nil, errorx.Wrap(locker.ErrLocked, "lock %s", name)
+	}
+
+	token, err := l.nextToken(ctx, name)
+	if err != nil {
+		discard(conn)
+
+		return nil, err
+	}
+
+	lk := &AdvisoryLock{
+		conn:     conn,
+		key:      key,
+		token:    token,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+		lost:     make(chan struct{}),
+		stopOnce: sync.Once{},
+		lostOnce: sync.Once{},
+	}
+
+	go lk.check(context.WithoutCancel(ctx), l.checkInterval)
+
+	return lk, nil
+}
+
+func acquireAdvisory(ctx context.Context, conn *sqlx.Conn, key int64, o locker.LockOptions) (bool, error) {
+	if !o.Wait {
+		var acquired bool
+
+		err := conn.GetContext(ctx, &acquired, `SELECT pg_try_advisory_lock($1)`, key)
+
+		return acquired, errorx.Wrap(err, "try advisory lock")
+	}
+
+	waitCtx := ctx
+
+	if o.Timeout > 0 {
+		var cancel context.CancelFunc
+
+		waitCtx, cancel = context.WithTimeout(ctx, o.Timeout)
+		defer cancel()
+	}
+
+	_, err := conn.ExecContext(waitCtx, `SELECT pg_advisory_lock($1)`, key)
+	if err != nil && ctx.Err() == nil && errors.Is(waitCtx.Err(), context.DeadlineExceeded) {
+		return false, nil
+	}
+
+	return err == nil, errorx.Wrap(err, "wait for advisory lock")
+}
+
+// discard closes the connection instead of returning it to the pool,
+// so that advisory locks it might hold are released.
+func discard(conn *sqlx.Conn) {
+	_ = conn.Raw(func(any) error {
+		return driver.ErrBadConn
+	})
+
+	_ = conn.Close()
+}
+
+type AdvisoryLock struct {
+	conn     *sqlx.Conn
+	key      int64
+	token    uint64
+	stop     chan struct{}
+	done     chan struct{}
+	lost     chan struct{}
+	stopOnce sync.Once
+	lostOnce sync.Once
+}
+
+func (lk *AdvisoryLock) Unlock(ctx context.Context) error {
+	lk.stopOnce.Do(func() {
+		close(lk.stop)
+	})
+
+	<-lk.done
+
+	select {
+	case <-lk.lost:
+		return errorx.IllegalState.New("connection of the lock was lost")
+	default:
+	}
+
+	var released bool
+
+	err := lk.conn.GetContext(ctx, &released, `SELECT pg_advisory_unlock($1)`, lk.key)
+	if err != nil || !released {
+		discard(lk.conn)
+
+		if err != nil {
+			return errorx.Wrap(err, "release advisory lock")
+		}
+
+		return errorx.IllegalState.New("advisory lock was not held")
+	}
+
+	return errorx.Wrap(lk.conn.Close(), "close connection")
+}
+
+// Lost is closed when the connection holding the lock fails, the lock is released by the database then.
+func (lk *AdvisoryLock) Lost() <-chan struct{} {
+	return lk.lost
+}
+
+func (lk *AdvisoryLock) Token() uint64 {
+	return lk.token
+}
+
+func (lk *AdvisoryLock) check(ctx context.Context, interval time.Duration) {
+	defer close(lk.done)
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-lk.stop:
+			return
+		case <-t.C:
+			_, err := lk.conn.ExecContext(ctx, `SELECT 1`)
+			if err == nil {
+				continue
+			}
+
+			zerolog.Ctx(ctx).Error().Err(err).Msg("Lost connection holding advisory lock")
+
+			discard(lk.conn)
+
+			lk.lostOnce.Do(func() {
+				close(lk.lost)
+			})
+
+			return
+		}
+	}
+}
+
+//nolint:ireturn // same as Lock.
+func (l *Locker) lockTable(ctx context.Context, name string) (locker.Lock, error) {
+	_, err := l.db.ExecContext(ctx, `INSERT INTO `+locksTable+` (name) VALUES ($1)`, name)
+	if gsqlx.IsDuplicateKey(err) {
+		return nil, errorx.Wrap(locker.ErrLocked, "lock %s", name)
+	}
+
+	if err != nil {
+		return nil, errorx.Wrap(err, "insert lock")
+	}
+
+	lk := &TableLock{
+		db:    l.db,
+		name:  name,
+		token: 0,
+		lost:  make(chan struct{}),
+	}
+
+	lk.token, err = l.nextToken(ctx, name)
+	if err != nil {
+		return nil, errors.Join(err, lk.Unlock(ctx))
+	}
+
+	return lk, nil
+}
+
+type TableLock struct {
+	db    *sqlx.DB
+	name  string
+	token uint64
+	lost  chan struct{}
+}
+
+func (lk *TableLock) Unlock(ctx context.Context) error {
+	_, err := lk.db.ExecContext(ctx, `DELETE FROM `+locksTable+` WHERE name = $1`, lk.name)
+
+	return errorx.Wrap(err, "delete lock")
+}
+
+// Lost is never closed, since table locks are held until unlocked.
+func (lk *TableLock) Lost() <-chan struct{} {
+	return lk.lost
+}
+
+func (lk *TableLock) Token() uint64 {
+	return lk.token
+}
@@ -0,0 +1,33 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sovamorco/gommon/locker"
+	_ "github.com/sovamorco/gommon/locker/postgres"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryFallback(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	l, err := locker.New(ctx, locker.Config{Provider: "postgres", URL: "memory://"})
+	require.NoError(t, err)
+
+	first, err := l.Lock(ctx, "lock")
+	require.NoError(t, err)
+
+	_, err = l.Lock(ctx, "lock", locker.WithWait(50*time.Millisecond), locker.WithRetryDelay(10*time.Millisecond))
+	require.ErrorIs(t, err, locker.ErrLocked)
+
+	require.NoError(t, first.Unlock(ctx))
+
+	second, err := l.Lock(ctx, "lock")
+	require.NoError(t, err)
+	require.Greater(t, second.Token(), first.Token())
+	require.NoError(t, second.Unlock(ctx))
+}
@@ -27,6 +27,15 @@ func (e UnknownProviderError) Error() string {
 	return fmt.Sprintf("locker: unknown provider %q", e.Provider)
 }
 
+type UnsupportedError struct {
+	Provider string
+	Feature  string
+}
+
+func (e UnsupportedError) Error() string {
+	return fmt.Sprintf("locker: provider %q does not support %s", e.Provider, e.Feature)
+}
+
 func Register(p string, bf builder) {
 	providersMu.Lock()
 	defer providersMu.Unlock()
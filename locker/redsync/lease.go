@@ -0,0 +1,126 @@
+package redlock
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// errNotHeld is returned by extendFunc when there is nothing to extend anymore.
+var errNotHeld = errors.New("no longer held")
+
+// extendFunc extends the lease, returning its new expiry.
+type extendFunc func(ctx context.Context) (time.Time, error)
+
+// lease is shared by locks and permits, it keeps them alive and tracks their loss.
+type lease struct {
+	token         uint64
+	stop          chan struct{}
+	lost          chan struct{}
+	extendFailure atomic.Pointer[func(err error)] `exhaustruct:"optional"`
+	stopOnce      sync.Once                       `exhaustruct:"optional"`
+	lostOnce      sync.Once                       `exhaustruct:"optional"`
+}
+
+func newLease(token uint64) *lease {
+	return &lease{
+		token: token,
+		stop:  make(chan struct{}),
+		lost:  make(chan struct{}),
+	}
+}
+
+// Lost is closed when the lock expires without being extended or is found to be taken by someone else.
+// the lock is no longer extended once ctx passed to Lock is done, so it is lost at its expiry then.
+func (l *lease) Lost() <-chan struct{} {
+	return l.lost
+}
+
+func (l *lease) Token() uint64 {
+	return l.token
+}
+
+// OnExtendFailure sets f called on every failed extension, including the ones that are retried.
+func (l *lease) OnExtendFailure(f func(err error)) {
+	l.extendFailure.Store(&f)
+}
+
+func (l *lease) markLost() {
+	l.lostOnce.Do(func() {
+		close(l.lost)
+	})
+}
+
+func (l *lease) release() {
+	l.stopOnce.Do(func() {
+		close(l.stop)
+	})
+}
+
+// keep calls extend every time the lease is policy.ExtendBuffer away from expiring, until it is released.
+// if auto-extension is disabled, the lease is only marked lost once it expires.
+func (l *lease) keep(ctx context.Context, what string, until time.Time, policy Policy, extend extendFunc) {
+	logger := zerolog.Ctx(ctx)
+
+	if !policy.AutoExtend {
+		go l.expireAt(until)
+
+		return
+	}
+
+	t := time.NewTimer(time.Until(until) - policy.ExtendBuffer)
+
+	go func() {
+		defer t.Stop()
+
+		for {
+			select {
+			case <-l.stop:
+				return
+			case <-ctx.Done():
+				l.expireAt(until)
+
+				return
+			case <-t.C:
+				newUntil, err := extend(ctx)
+				if err == nil {
+					until = newUntil
+					t.Reset(time.Until(until) - policy.ExtendBuffer)
+
+					continue
+				}
+
+				logger.Error().Err(err).Msg("Failed to extend " + what)
+
+				if f := l.extendFailure.Load(); f != nil {
+					(*f)(err)
+				}
+
+				timeRemaining := time.Until(until)
+				if errors.Is(err, errNotHeld) || timeRemaining <= 0 {
+					l.markLost()
+
+					return
+				}
+
+				t.Reset(min(extendRetryDelay, timeRemaining))
+			}
+		}
+	}()
+}
+
+// expireAt marks the lease lost at until unless it is released earlier.
+func (l *lease) expireAt(until time.Time) {
+	t := time.NewTimer(time.Until(until))
+	defer t.Stop()
+
+	select {
+	case <-l.stop:
+	case <-t.C:
+		l.markLost()
+	}
+}
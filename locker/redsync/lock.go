@@ -2,24 +2,21 @@ package redlock
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/go-redsync/redsync/v4"
-	"github.com/rs/zerolog"
 	"github.com/sovamorco/errorx"
 )
 
-const (
-	extendBuffer = 5 * time.Second
-)
-
 type Lock struct {
+	*lease
+
 	mutex *redsync.Mutex
-	stop  chan struct{}
 }
 
 func (l *Lock) Unlock(ctx context.Context) error {
-	close(l.stop)
+	l.release()
 
 	_, err := l.mutex.UnlockContext(ctx)
 	if err != nil {
@@ -29,29 +26,23 @@ func (l *Lock) Unlock(ctx context.Context) error {
 	return nil
 }
 
-func (l *Lock) startExtension(ctx context.Context) {
-	logger := zerolog.Ctx(ctx)
-
-	timeRemaining := time.Until(l.mutex.Until())
-
-	t := time.NewTimer(timeRemaining - extendBuffer)
-
-	go func() {
-		for {
-			select {
-			case <-l.stop:
-				return
-			case <-ctx.Done():
-				return
-			case <-t.C:
-				_, err := l.mutex.ExtendContext(ctx)
-				if err != nil {
-					logger.Error().Err(err).Msg("Failed to extend mutex")
-				}
-
-				timeRemaining = time.Until(l.mutex.Until())
-				t.Reset(timeRemaining - extendBuffer)
-			}
-		}
-	}()
+func (l *Lock) extend(ctx context.Context) (time.Time, error) {
+	ok, err := l.mutex.ExtendContext(ctx)
+	if ok {
+		return l.mutex.Until(), nil
+	}
+
+	if lostOnExtend(err) {
+		return time.Time{}, errors.Join(errNotHeld, err)
+	}
+
+	return time.Time{}, errorx.Wrap(err, "extend mutex")
+}
+
+// lostOnExtend reports whether extension failed because the lock is no longer held, as opposed to connection errors.
+func lostOnExtend(err error) bool {
+	var nodeTaken *redsync.ErrNodeTaken
+
+	return err == nil || errors.Is(err, redsync.ErrExtendFailed) || errors.Is(err, redsync.ErrLockAlreadyExpired) ||
+		errors.As(err, &nodeTaken)
 }
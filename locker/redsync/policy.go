@@ -0,0 +1,140 @@
+package redlock
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/sovamorco/errorx"
+	"github.com/sovamorco/gommon/locker"
+)
+
+const (
+	ExpiryTime   = 15 * time.Second
+	ExtendBuffer = 5 * time.Second
+
+	extendRetryDelay = time.Second
+)
+
+// Policy configures expiry and extension of locks and permits.
+// it can be set through connection url query parameters, e.g.
+//
+//	redis://localhost:6379/0?expiry=30s&extend_buffer=10s&auto_extend=false&tries=3&retry_delay=100ms#prefix
+type Policy struct {
+	Expiry time.Duration
+	// how long before expiry the lock is extended, has to be less than Expiry if AutoExtend is set.
+	ExtendBuffer time.Duration
+	AutoExtend   bool
+
+	// attempts redsync makes on each try to acquire the lock, with RetryDelay between them.
+	// redsync picks a random delay if RetryDelay is zero. can be set per call with locker.WithTries.
+	Tries      int
+	RetryDelay time.Duration
+}
+
+func DefaultPolicy() Policy {
+	return Policy{
+		Expiry:       ExpiryTime,
+		ExtendBuffer: ExtendBuffer,
+		AutoExtend:   true,
+		Tries:        1,
+		RetryDelay:   0,
+	}
+}
+
+func (p Policy) Validate() error {
+	if p.Expiry <= 0 {
+		return errorx.IllegalArgument.New("expiry must be positive, got %s", p.Expiry)
+	}
+
+	if p.AutoExtend && (p.ExtendBuffer <= 0 || p.ExtendBuffer >= p.Expiry) {
+		return errorx.IllegalArgument.New("extend buffer must be positive and less than expiry %s, got %s",
+			p.Expiry, p.ExtendBuffer)
+	}
+
+	if p.Tries <= 0 {
+		return errorx.IllegalArgument.New("tries must be positive, got %d", p.Tries)
+	}
+
+	if p.RetryDelay < 0 {
+		return errorx.IllegalArgument.New("retry delay must not be negative, got %s", p.RetryDelay)
+	}
+
+	return nil
+}
+
+// with applies per-call options over the policy.
+func (p Policy) with(o locker.LockOptions) (Policy, error) {
+	if o.Expiry != 0 {
+		p.Expiry = o.Expiry
+	}
+
+	if o.ExtendBuffer != 0 {
+		p.ExtendBuffer = o.ExtendBuffer
+	}
+
+	if o.AutoExtend != nil {
+		p.AutoExtend = *o.AutoExtend
+	}
+
+	if o.Tries != 0 {
+		p.Tries = o.Tries
+	}
+
+	if o.TryDelay != 0 {
+		p.RetryDelay = o.TryDelay
+	}
+
+	return p, p.Validate()
+}
+
+// parsePolicy reads policy from query parameters of u, removing them so that the rest can be passed to gredis.
+func parsePolicy(u *url.URL) (Policy, error) {
+	p := DefaultPolicy()
+	q := u.Query()
+
+	for _, d := range []struct {
+		param string
+		dst   *time.Duration
+	}{
+		{"expiry", &p.Expiry},
+		{"extend_buffer", &p.ExtendBuffer},
+		{"retry_delay", &p.RetryDelay},
+	} {
+		if v := q.Get(d.param); v != "" {
+			parsed, err := time.ParseDuration(v)
+			if err != nil {
+				return p, errorx.Wrap(err, "parse %s", d.param)
+			}
+
+			*d.dst = parsed
+		}
+
+		q.Del(d.param)
+	}
+
+	if v := q.Get("auto_extend"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			return p, errorx.Wrap(err, "parse auto_extend")
+		}
+
+		p.AutoExtend = parsed
+	}
+
+	if v := q.Get("tries"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil {
+			return p, errorx.Wrap(err, "parse tries")
+		}
+
+		p.Tries = parsed
+	}
+
+	q.Del("auto_extend")
+	q.Del("tries")
+
+	u.RawQuery = q.Encode()
+
+	return p, p.Validate()
+}
@@ -2,19 +2,40 @@ package redlock
 
 import (
 	"context"
+	"errors"
 	"net/url"
-	"time"
 
 	"github.com/go-redsync/redsync/v4"
 	rsredis "github.com/go-redsync/redsync/v4/redis/goredis/v9"
+	"github.com/redis/go-redis/v9"
 	"github.com/sovamorco/errorx"
 	"github.com/sovamorco/gommon/gredis"
 	"github.com/sovamorco/gommon/locker"
 )
 
-const (
-	ExpiryTime = 15 * time.Second
-)
+// fencing counters expire with the lock, so that they are not kept for every name ever locked.
+// tokens are at least the server time in microseconds, which keeps them increasing after the counter expires,
+// as long as the server clock does not go backwards. the counter only orders acquisitions within the same microsecond.
+const fenceLua = `
+local function nextToken(key, ttl)
+	local time = redis.call('TIME')
+	local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+	local token = math.max((tonumber(redis.call('GET', key)) or 0) + 1, now)
+	redis.call('SET', key, string.format('%.0f', token), 'PX', ttl)
+	return token
+end
+`
+
+// fenceScript issues the next fencing token only while the mutex is still held with our value,
+// so that a holder whose mutex expired before getting the token cannot outrun the next holder.
+//
+//nolint:gochecknoglobals // scripts are loaded once.
+var fenceScript = redis.NewScript(fenceLua + `
+if redis.call('GET', KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+return nextToken(KEYS[2], ARGV[2])
+`)
 
 //nolint:gochecknoinits // driver pattern.
 func init() {
@@ -22,8 +43,10 @@ func init() {
 }
 
 type Redsync struct {
+	cl     redis.UniversalClient
 	rs     *redsync.Redsync
 	prefix string
+	policy Policy
 }
 
 //nolint:ireturn // required by locker.Register.
@@ -33,38 +56,109 @@ func newRedsync(ctx context.Context, connst string) (locker.Locker, error) {
 		return nil, errorx.Wrap(err, "parse connection url")
 	}
 
-	cl, err := gredis.New(ctx, connst)
+	policy, err := parsePolicy(u)
 	if err != nil {
-		return nil, errorx.Wrap(err, "create redis client")
+		return nil, errorx.Wrap(err, "parse policy")
 	}
 
-	rsp := rsredis.NewPool(cl)
+	cl, err := gredis.NewShared(ctx, u.String())
+	if err != nil {
+		return nil, errorx.Wrap(err, "create redis client")
+	}
 
-	rs := redsync.New(rsp)
+	return NewWithPolicy(cl, u.Fragment, policy)
+}
 
+// New creates a locker over an existing client with DefaultPolicy, lock names are prefixed with prefix.
+func New(cl redis.UniversalClient, prefix string) *Redsync {
 	return &Redsync{
-		rs:     rs,
-		prefix: u.Fragment,
-	}, nil
+		cl:     cl,
+		rs:     redsync.New(rsredis.NewPool(cl)),
+		prefix: prefix,
+		policy: DefaultPolicy(),
+	}
 }
 
-// interface return required by interface.
+func NewWithPolicy(cl redis.UniversalClient, prefix string, policy Policy) (*Redsync, error) {
+	err := policy.Validate()
+	if err != nil {
+		return nil, errorx.Wrap(err, "validate policy")
+	}
+
+	rl := New(cl, prefix)
+	rl.policy = policy
+
+	return rl, nil
+}
+
+// Close closes the underlying client.
+func (rl *Redsync) Close() error {
+	return errorx.Wrap(rl.cl.Close(), "close redis client")
+}
+
+// Lock applies expiry, extend buffer, auto extend and tries from opts on top of the policy.
+// wait and retry delay only affect retries of locker.Retry around redsync tries.
 //
-//nolint:ireturn
-func (rl *Redsync) Lock(ctx context.Context, name string) (locker.Lock, error) {
-	mutex := rl.rs.NewMutex(rl.prefix+":"+name, redsync.WithExpiry(ExpiryTime))
+//nolint:ireturn // interface return required by interface.
+func (rl *Redsync) Lock(ctx context.Context, name string, opts ...locker.LockOption) (locker.Lock, error) {
+	o := locker.NewLockOptions(opts...)
+
+	policy, err := rl.policy.with(o)
+	if err != nil {
+		return nil, errorx.Wrap(err, "apply lock options")
+	}
+
+	return locker.Retry(ctx, o, func() (locker.Lock, error) {
+		return rl.tryLock(ctx, name, policy)
+	})
+}
+
+//nolint:ireturn // same as Lock.
+func (rl *Redsync) tryLock(ctx context.Context, name string, policy Policy) (locker.Lock, error) {
+	mopts := []redsync.Option{redsync.WithExpiry(policy.Expiry), redsync.WithTries(policy.Tries)}
+	if policy.RetryDelay > 0 {
+		mopts = append(mopts, redsync.WithRetryDelay(policy.RetryDelay))
+	}
+
+	mutex := rl.rs.NewMutex(rl.prefix+":"+name, mopts...)
+
+	err := mutex.LockContext(ctx)
+	if err != nil {
+		return nil, lockError(err)
+	}
 
-	err := mutex.TryLockContext(ctx)
+	token, err := fenceScript.Run(ctx, rl.cl, []string{mutex.Name(), gredis.SlotKey(mutex.Name(), ":fence")},
+		mutex.Value(), policy.Expiry.Milliseconds()).Uint64()
 	if err != nil {
-		return nil, errorx.Wrap(err, "lock mutex")
+		_, uerr := mutex.UnlockContext(ctx)
+
+		return nil, errorx.Wrap(errors.Join(err, uerr), "run fence script")
+	}
+
+	if token == 0 {
+		return nil, errorx.IllegalState.New("lock %s expired before fencing token was issued", name)
 	}
 
 	l := &Lock{
+		lease: newLease(token),
 		mutex: mutex,
-		stop:  make(chan struct{}),
 	}
 
-	l.startExtension(ctx)
+	l.keep(ctx, "mutex", mutex.Until(), policy, l.extend)
 
 	return l, nil
 }
+
+// lockError maps contention errors from redsync to locker.ErrLocked.
+func lockError(err error) error {
+	var (
+		taken     *redsync.ErrTaken
+		nodeTaken *redsync.ErrNodeTaken
+	)
+
+	if errors.Is(err, redsync.ErrFailed) || errors.As(err, &taken) || errors.As(err, &nodeTaken) {
+		return errorx.Wrap(locker.ErrLocked, "lock mutex")
+	}
+
+	return errorx.Wrap(err, "lock mutex")
+}
@@ -0,0 +1,293 @@
+package redlock_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/sovamorco/errorx"
+	"github.com/sovamorco/gommon/gredis"
+	"github.com/sovamorco/gommon/locker"
+	redlock "github.com/sovamorco/gommon/locker/redsync"
+	"github.com/sovamorco/gommon/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRedsync(t *testing.T) *redlock.Redsync {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+
+	cl, err := gredis.NewClient("redis://" + mr.Addr())
+	require.NoError(t, err)
+
+	rl := redlock.New(cl, "test")
+	t.Cleanup(func() { _ = rl.Close() })
+
+	return rl
+}
+
+func TestLockWait(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	rl := newRedsync(t)
+
+	held, err := rl.Lock(ctx, "lock")
+	require.NoError(t, err)
+
+	_, err = rl.Lock(ctx, "lock")
+	require.ErrorIs(t, err, locker.ErrLocked)
+
+	_, err = rl.Lock(ctx, "lock", locker.WithWait(50*time.Millisecond), locker.WithRetryDelay(10*time.Millisecond))
+	require.ErrorIs(t, err, locker.ErrLocked)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+
+		locker.UnlockLog(ctx, held)
+	}()
+
+	l, err := rl.Lock(ctx, "lock", locker.WithWait(0), locker.WithRetryDelay(10*time.Millisecond))
+	require.NoError(t, err)
+	require.NoError(t, l.Unlock(ctx))
+}
+
+func TestLockTokenAndLost(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rl := newRedsync(t)
+
+	first, err := rl.Lock(ctx, "lock")
+	require.NoError(t, err)
+	require.NoError(t, first.Unlock(ctx))
+
+	second, err := rl.Lock(ctx, "lock", locker.WithExpiry(200*time.Millisecond),
+		locker.WithExtendBuffer(100*time.Millisecond))
+	require.NoError(t, err)
+	require.Greater(t, second.Token(), first.Token())
+
+	cancel()
+
+	select {
+	case <-second.Lost():
+		t.Fatal("lock lost before it expired")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	select {
+	case <-second.Lost():
+	case <-time.After(time.Second):
+		t.Fatal("lock not lost after it expired without extension")
+	}
+}
+
+func TestExtendFailures(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+
+	cl, err := gredis.NewClient("redis://" + mr.Addr())
+	require.NoError(t, err)
+
+	m := metrics.NewMemory(nil)
+	l := locker.Instrument(redlock.New(cl, "test"), m)
+
+	lk, err := l.Lock(ctx, "lock", locker.WithExpiry(300*time.Millisecond),
+		locker.WithExtendBuffer(250*time.Millisecond))
+	require.NoError(t, err)
+
+	mr.Close()
+
+	select {
+	case <-lk.Lost():
+	case <-time.After(time.Second):
+		t.Fatal("lock not lost after failing to extend")
+	}
+
+	labels := metrics.Labels{"pattern": "lock"}
+
+	assert.GreaterOrEqual(t, m.Counter(locker.MetricExtendFailures, labels), 1.0)
+	assert.Eventually(t, func() bool {
+		return m.Counter(locker.MetricLost, labels) == 1
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestPolicy(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+
+	_, err := locker.New(ctx, locker.Config{
+		Provider: "redis",
+		URL:      "redis://" + mr.Addr() + "?expiry=5s&extend_buffer=5s#test",
+	})
+	require.Error(t, err)
+
+	l, err := locker.New(ctx, locker.Config{
+		Provider: "redis",
+		URL:      "redis://" + mr.Addr() + "?expiry=10s&auto_extend=false&tries=2&retry_delay=10ms#test",
+	})
+	require.NoError(t, err)
+
+	_, err = l.Lock(ctx, "lock", locker.WithAutoExtend(true), locker.WithExpiry(time.Second),
+		locker.WithExtendBuffer(2*time.Second))
+	require.Error(t, err)
+
+	lk, err := l.Lock(ctx, "lock", locker.WithExpiry(100*time.Millisecond))
+	require.NoError(t, err)
+
+	select {
+	case <-lk.Lost():
+	case <-time.After(time.Second):
+		t.Fatal("lock not lost after expiry")
+	}
+
+	_, err = l.Lock(ctx, "tries")
+	require.NoError(t, err)
+
+	start := time.Now()
+
+	_, err = l.Lock(ctx, "tries", locker.WithTries(3, 50*time.Millisecond))
+	require.ErrorIs(t, err, locker.ErrLocked)
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+
+	_, err = l.Lock(ctx, "tries", locker.WithTries(-1, 0))
+	require.Error(t, err)
+}
+
+func TestReentrant(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	rl := newRedsync(t)
+
+	_, err := rl.LockReentrant(ctx, "lock")
+	require.ErrorIs(t, err, locker.ErrNoOwner)
+
+	first := locker.WithOwner(ctx, "first")
+
+	outer, err := rl.LockReentrant(first, "lock")
+	require.NoError(t, err)
+
+	inner, err := rl.LockReentrant(first, "lock")
+	require.NoError(t, err)
+	require.Equal(t, outer.Token(), inner.Token())
+
+	second := locker.WithOwner(ctx, "second")
+
+	_, err = rl.LockReentrant(second, "lock")
+	require.ErrorIs(t, err, locker.ErrLocked)
+
+	require.NoError(t, inner.Unlock(first))
+	require.Error(t, inner.Unlock(first))
+
+	_, err = rl.LockReentrant(second, "lock")
+	require.ErrorIs(t, err, locker.ErrLocked)
+
+	require.NoError(t, outer.Unlock(first))
+
+	l, err := rl.LockReentrant(second, "lock")
+	require.NoError(t, err)
+	require.Greater(t, l.Token(), outer.Token())
+	require.NoError(t, l.Unlock(second))
+}
+
+func TestRW(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	rw := newRedsync(t).RW()
+
+	r1, err := rw.RLock(ctx, "lock")
+	require.NoError(t, err)
+
+	r2, err := rw.RLock(ctx, "lock")
+	require.NoError(t, err)
+
+	_, err = rw.Lock(ctx, "lock")
+	require.ErrorIs(t, err, locker.ErrLocked)
+
+	require.NoError(t, r1.Unlock(ctx))
+	require.NoError(t, r2.Unlock(ctx))
+
+	w, err := rw.Lock(ctx, "lock")
+	require.NoError(t, err)
+
+	_, err = rw.RLock(ctx, "lock")
+	require.ErrorIs(t, err, locker.ErrLocked)
+
+	require.NoError(t, w.Unlock(ctx))
+
+	r3, err := rw.RLock(ctx, "lock")
+	require.NoError(t, err)
+	require.NoError(t, r3.Unlock(ctx))
+}
+
+func TestRWUnlockExpired(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+
+	cl, err := gredis.NewClient("redis://" + mr.Addr())
+	require.NoError(t, err)
+
+	rw := redlock.New(cl, "test").RW()
+
+	w, err := rw.Lock(ctx, "lock", locker.WithExpiry(time.Second), locker.WithAutoExtend(false))
+	require.NoError(t, err)
+
+	mr.FastForward(2 * time.Second)
+
+	err = w.Unlock(ctx)
+	require.Error(t, err)
+	require.True(t, errorx.IsOfType(err, errorx.IllegalState))
+}
+
+func TestRWReadersExpire(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+
+	cl, err := gredis.NewClient("redis://" + mr.Addr())
+	require.NoError(t, err)
+
+	rw := redlock.New(cl, "test").RW()
+
+	_, err = rw.RLock(ctx, "lock", locker.WithExpiry(time.Second), locker.WithAutoExtend(false))
+	require.NoError(t, err)
+
+	ttl := mr.TTL("{test:__rw:lock}:readers")
+	assert.Positive(t, ttl)
+	assert.LessOrEqual(t, ttl, time.Second)
+}
+
+func TestFenceExpires(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+
+	cl, err := gredis.NewClient("redis://" + mr.Addr())
+	require.NoError(t, err)
+
+	rl := redlock.New(cl, "test")
+
+	first, err := rl.Lock(ctx, "{tag}:lock", locker.WithExpiry(time.Second), locker.WithAutoExtend(false))
+	require.NoError(t, err)
+	assert.True(t, mr.Exists("test:{tag}:lock:fence"))
+
+	mr.FastForward(2 * time.Second)
+	assert.False(t, mr.Exists("test:{tag}:lock:fence"))
+
+	second, err := rl.Lock(ctx, "{tag}:lock", locker.WithExpiry(time.Second), locker.WithAutoExtend(false))
+	require.NoError(t, err)
+	assert.Greater(t, second.Token(), first.Token())
+}
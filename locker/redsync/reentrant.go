@@ -0,0 +1,148 @@
+package redlock
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sovamorco/errorx"
+	"github.com/sovamorco/gommon/gredis"
+	"github.com/sovamorco/gommon/locker"
+)
+
+// reentrant locks are hashes with owner, acquisition count and fencing token.
+// nested acquisitions never shorten the expiry set by the others.
+//
+//nolint:gochecknoglobals // scripts are loaded once.
+var (
+	reentrantLockScript = redis.NewScript(fenceLua + `
+local owner = redis.call('HGET', KEYS[1], 'owner')
+if owner and owner ~= ARGV[1] then
+	return 0
+end
+if owner then
+	redis.call('HINCRBY', KEYS[1], 'count', 1)
+else
+	redis.call('HSET', KEYS[1], 'owner', ARGV[1], 'count', 1, 'token', nextToken(KEYS[2], ARGV[2]))
+end
+if redis.call('PTTL', KEYS[1]) < tonumber(ARGV[2]) then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return tonumber(redis.call('HGET', KEYS[1], 'token'))
+`)
+	reentrantExtendScript = redis.NewScript(`
+if redis.call('HGET', KEYS[1], 'owner') ~= ARGV[1] then
+	return 0
+end
+if redis.call('PTTL', KEYS[1]) < tonumber(ARGV[2]) then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 1
+`)
+	reentrantUnlockScript = redis.NewScript(`
+if redis.call('HGET', KEYS[1], 'owner') ~= ARGV[1] then
+	return -1
+end
+local count = redis.call('HINCRBY', KEYS[1], 'count', -1)
+if count <= 0 then
+	redis.call('DEL', KEYS[1])
+end
+return count
+`)
+)
+
+type ReentrantLock struct {
+	*lease
+
+	parent *Redsync
+	key    string
+	owner  string
+	policy Policy
+	// handles share the hold count of the owner, so each of them may only lower it once.
+	unlocked atomic.Bool `exhaustruct:"optional"`
+}
+
+// interface return required by interface.
+//
+//nolint:ireturn
+func (rl *Redsync) LockReentrant(ctx context.Context, name string, opts ...locker.LockOption) (locker.Lock, error) {
+	owner, ok := locker.OwnerFromContext(ctx)
+	if !ok {
+		return nil, locker.ErrNoOwner
+	}
+
+	o := locker.NewLockOptions(opts...)
+
+	policy, err := rl.policy.with(o)
+	if err != nil {
+		return nil, errorx.Wrap(err, "apply lock options")
+	}
+
+	return locker.Retry(ctx, o, func() (locker.Lock, error) {
+		return rl.tryLockReentrant(ctx, name, owner, policy)
+	})
+}
+
+//nolint:ireturn // same as LockReentrant.
+func (rl *Redsync) tryLockReentrant(ctx context.Context, name, owner string, policy Policy) (locker.Lock, error) {
+	key := gredis.SlotKey(rl.prefix+":__reentrant:"+name, "")
+	until := time.Now().Add(policy.Expiry)
+
+	token, err := reentrantLockScript.Run(ctx, rl.cl, []string{key, gredis.SlotKey(key, ":fence")},
+		owner, policy.Expiry.Milliseconds()).Uint64()
+	if err != nil {
+		return nil, errorx.Wrap(err, "run lock script")
+	}
+
+	if token == 0 {
+		return nil, errorx.Wrap(locker.ErrLocked, "lock %s", name)
+	}
+
+	l := &ReentrantLock{
+		lease:  newLease(token),
+		parent: rl,
+		key:    key,
+		owner:  owner,
+		policy: policy,
+	}
+
+	l.keep(ctx, "reentrant lock", until, policy, l.extend)
+
+	return l, nil
+}
+
+func (l *ReentrantLock) Unlock(ctx context.Context) error {
+	if !l.unlocked.CompareAndSwap(false, true) {
+		return errorx.IllegalState.New("lock is already unlocked")
+	}
+
+	l.release()
+
+	count, err := reentrantUnlockScript.Run(ctx, l.parent.cl, []string{l.key}, l.owner).Int()
+	if err != nil {
+		return errorx.Wrap(err, "run unlock script")
+	}
+
+	if count < 0 {
+		return errorx.IllegalState.New("lock is not held by %s", l.owner)
+	}
+
+	return nil
+}
+
+func (l *ReentrantLock) extend(ctx context.Context) (time.Time, error) {
+	until := time.Now().Add(l.policy.Expiry)
+
+	ok, err := reentrantExtendScript.Run(ctx, l.parent.cl, []string{l.key}, l.owner,
+		l.policy.Expiry.Milliseconds()).Bool()
+	if err != nil {
+		return time.Time{}, errorx.Wrap(err, "run extend script")
+	}
+
+	if !ok {
+		return time.Time{}, errNotHeld
+	}
+
+	return until, nil
+}
@@ -0,0 +1,185 @@
+package redlock
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/sovamorco/errorx"
+	"github.com/sovamorco/gommon/gredis"
+	"github.com/sovamorco/gommon/locker"
+)
+
+// writer holds a string key, readers are kept in a sorted set scored by expiry like semaphore permits.
+// every acquisition issues the next fencing token from the counter in the third key.
+//
+//nolint:gochecknoglobals // scripts are loaded once.
+var (
+	readLockScript = redis.NewScript(nowLua + fenceLua + `
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return 0
+end
+redis.call('ZREMRANGEBYSCORE', KEYS[2], '-inf', now)
+redis.call('ZADD', KEYS[2], now + tonumber(ARGV[2]), ARGV[1])
+` + expireLua + `
+expireSet(KEYS[2])
+return nextToken(KEYS[3], ARGV[2])
+`)
+	writeLockScript = redis.NewScript(nowLua + fenceLua + `
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return 0
+end
+redis.call('ZREMRANGEBYSCORE', KEYS[2], '-inf', now)
+if redis.call('ZCARD', KEYS[2]) > 0 then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+return nextToken(KEYS[3], ARGV[2])
+`)
+	writeExtendScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+`)
+	writeUnlockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+return redis.call('DEL', KEYS[1])
+`)
+)
+
+type RWLocker struct {
+	parent *Redsync
+}
+
+// RW returns read/write locker sharing client and policy with rl.
+//
+//nolint:ireturn // required by locker.RWProvider.
+func (rl *Redsync) RW() locker.RWLocker {
+	return &RWLocker{
+		parent: rl,
+	}
+}
+
+// interface return required by interface.
+//
+//nolint:ireturn
+func (rw *RWLocker) RLock(ctx context.Context, name string, opts ...locker.LockOption) (locker.Lock, error) {
+	return rw.lock(ctx, name, false, opts)
+}
+
+// interface return required by interface.
+//
+//nolint:ireturn
+func (rw *RWLocker) Lock(ctx context.Context, name string, opts ...locker.LockOption) (locker.Lock, error) {
+	return rw.lock(ctx, name, true, opts)
+}
+
+//nolint:ireturn // same as Lock.
+func (rw *RWLocker) lock(ctx context.Context, name string, write bool, opts []locker.LockOption) (locker.Lock, error) {
+	o := locker.NewLockOptions(opts...)
+
+	policy, err := rw.parent.policy.with(o)
+	if err != nil {
+		return nil, errorx.Wrap(err, "apply lock options")
+	}
+
+	return locker.Retry(ctx, o, func() (locker.Lock, error) {
+		return rw.tryLock(ctx, name, write, policy)
+	})
+}
+
+//nolint:ireturn // same as Lock.
+func (rw *RWLocker) tryLock(ctx context.Context, name string, write bool, policy Policy) (locker.Lock, error) {
+	base := rw.parent.prefix + ":__rw:" + name
+
+	l := &RWLock{
+		lease:   nil,
+		parent:  rw.parent,
+		writer:  gredis.SlotKey(base, ":writer"),
+		readers: gredis.SlotKey(base, ":readers"),
+		id:      uuid.NewString(),
+		write:   write,
+		policy:  policy,
+	}
+
+	script := readLockScript
+	if write {
+		script = writeLockScript
+	}
+
+	until := time.Now().Add(policy.Expiry)
+
+	token, err := script.Run(ctx, rw.parent.cl, []string{l.writer, l.readers, gredis.SlotKey(base, ":fence")},
+		l.id, policy.Expiry.Milliseconds()).Uint64()
+	if err != nil {
+		return nil, errorx.Wrap(err, "run lock script")
+	}
+
+	if token == 0 {
+		return nil, errorx.Wrap(locker.ErrLocked, "lock %s", name)
+	}
+
+	l.lease = newLease(token)
+	l.keep(ctx, "read/write lock", until, policy, l.extend)
+
+	return l, nil
+}
+
+type RWLock struct {
+	*lease
+
+	parent  *Redsync
+	writer  string
+	readers string
+	id      string
+	write   bool
+	policy  Policy
+}
+
+func (l *RWLock) Unlock(ctx context.Context) error {
+	l.release()
+
+	var (
+		n   int64
+		err error
+	)
+
+	if l.write {
+		n, err = writeUnlockScript.Run(ctx, l.parent.cl, []string{l.writer}, l.id).Int64()
+	} else {
+		n, err = releaseScript.Run(ctx, l.parent.cl, []string{l.readers}, l.id).Int64()
+	}
+
+	if err != nil {
+		return errorx.Wrap(err, "run unlock script")
+	}
+
+	if n == 0 {
+		return errorx.IllegalState.New("read/write lock is not held")
+	}
+
+	return nil
+}
+
+func (l *RWLock) extend(ctx context.Context) (time.Time, error) {
+	if !l.write {
+		return extendMember(ctx, l.parent.cl, l.readers, l.id, l.policy.Expiry)
+	}
+
+	until := time.Now().Add(l.policy.Expiry)
+
+	ok, err := writeExtendScript.Run(ctx, l.parent.cl, []string{l.writer}, l.id, l.policy.Expiry.Milliseconds()).Bool()
+	if err != nil {
+		return time.Time{}, errorx.Wrap(err, "run extend script")
+	}
+
+	if !ok {
+		return time.Time{}, errNotHeld
+	}
+
+	return until, nil
+}
@@ -0,0 +1,127 @@
+package redlock
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/sovamorco/errorx"
+	"github.com/sovamorco/gommon/locker"
+)
+
+// permits are kept in a sorted set scored by their expiry in unix milliseconds of the server time,
+// so that permits of crashed holders are dropped without relying on their clocks.
+const nowLua = `
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+`
+
+// the whole set expires with its latest permit.
+const expireLua = `
+local function expireSet(key)
+	local last = redis.call('ZRANGE', key, -1, -1, 'WITHSCORES')
+	if last[2] then
+		redis.call('PEXPIRE', key, math.max(tonumber(last[2]) - now, 1))
+	end
+end
+`
+
+//nolint:gochecknoglobals // scripts are loaded once.
+var (
+	acquireScript = redis.NewScript(nowLua + `
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now)
+if redis.call('ZCARD', KEYS[1]) >= tonumber(ARGV[2]) then
+	return 0
+end
+redis.call('ZADD', KEYS[1], now + tonumber(ARGV[3]), ARGV[1])
+` + expireLua + `
+expireSet(KEYS[1])
+return 1
+`)
+	extendScript = redis.NewScript(nowLua + `
+local score = redis.call('ZSCORE', KEYS[1], ARGV[1])
+if not score or tonumber(score) <= now then
+	return 0
+end
+redis.call('ZADD', KEYS[1], 'XX', now + tonumber(ARGV[2]), ARGV[1])
+` + expireLua + `
+expireSet(KEYS[1])
+return 1
+`)
+	releaseScript = redis.NewScript(`
+return redis.call('ZREM', KEYS[1], ARGV[1])
+`)
+)
+
+type Permit struct {
+	*lease
+
+	parent *Redsync
+	key    string
+	id     string
+	policy Policy
+}
+
+// interface return required by interface.
+//
+//nolint:ireturn
+func (rl *Redsync) Acquire(ctx context.Context, name string, limit int) (locker.Permit, error) {
+	if limit <= 0 {
+		return nil, errorx.IllegalArgument.New("semaphore limit must be positive, got %d", limit)
+	}
+
+	p := &Permit{
+		lease:  newLease(0),
+		parent: rl,
+		key:    rl.prefix + ":__semaphore:" + name,
+		id:     uuid.NewString(),
+		policy: rl.policy,
+	}
+
+	until := time.Now().Add(p.policy.Expiry)
+
+	ok, err := acquireScript.Run(ctx, rl.cl, []string{p.key}, p.id, limit, p.policy.Expiry.Milliseconds()).Bool()
+	if err != nil {
+		return nil, errorx.Wrap(err, "run acquire script")
+	}
+
+	if !ok {
+		return nil, locker.ErrNoPermits
+	}
+
+	p.keep(ctx, "permit", until, p.policy, p.extend)
+
+	return p, nil
+}
+
+func (p *Permit) Release(ctx context.Context) error {
+	p.release()
+
+	err := releaseScript.Run(ctx, p.parent.cl, []string{p.key}, p.id).Err()
+	if err != nil {
+		return errorx.Wrap(err, "run release script")
+	}
+
+	return nil
+}
+
+func (p *Permit) extend(ctx context.Context) (time.Time, error) {
+	return extendMember(ctx, p.parent.cl, p.key, p.id, p.policy.Expiry)
+}
+
+// extendMember extends expiry of id in sorted set key, as used by semaphores and read locks.
+func extendMember(ctx context.Context, cl redis.UniversalClient, key, id string, expiry time.Duration) (time.Time, error) {
+	until := time.Now().Add(expiry)
+
+	ok, err := extendScript.Run(ctx, cl, []string{key}, id, expiry.Milliseconds()).Bool()
+	if err != nil {
+		return time.Time{}, errorx.Wrap(err, "run extend script")
+	}
+
+	if !ok {
+		return time.Time{}, errNotHeld
+	}
+
+	return until, nil
+}
@@ -0,0 +1,33 @@
+package redlock_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sovamorco/gommon/locker"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSemaphore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	rl := newRedsync(t)
+
+	first, err := rl.Acquire(ctx, "sem", 2)
+	require.NoError(t, err)
+
+	second, err := rl.Acquire(ctx, "sem", 2)
+	require.NoError(t, err)
+
+	_, err = rl.Acquire(ctx, "sem", 2)
+	require.ErrorIs(t, err, locker.ErrNoPermits)
+
+	require.NoError(t, first.Release(ctx))
+
+	third, err := rl.Acquire(ctx, "sem", 2)
+	require.NoError(t, err)
+
+	require.NoError(t, second.Release(ctx))
+	require.NoError(t, third.Release(ctx))
+}
@@ -0,0 +1,49 @@
+package locker
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrNoOwner = errors.New("no lock owner in context")
+
+type ownerKey struct{}
+
+// WithOwner sets owner of reentrant locks acquired with ctx.
+func WithOwner(ctx context.Context, owner string) context.Context {
+	return context.WithValue(ctx, ownerKey{}, owner)
+}
+
+func OwnerFromContext(ctx context.Context) (string, bool) {
+	owner, ok := ctx.Value(ownerKey{}).(string)
+
+	return owner, ok && owner != ""
+}
+
+// ReentrantLocker acquires locks that the same owner can acquire again while holding them.
+// the lock is released once each of the acquisitions is unlocked, and all of them share the fencing token.
+// reentrant locks are independent from the ones acquired with Locker.Lock, even with the same name.
+type ReentrantLocker interface {
+	// LockReentrant fails with ErrNoOwner if there is no owner in ctx, see WithOwner.
+	LockReentrant(ctx context.Context, name string, opts ...LockOption) (Lock, error)
+}
+
+// NewReentrant creates a reentrant locker from the provider in cfg, if the provider supports it.
+//
+//nolint:ireturn // depends on provider.
+func NewReentrant(ctx context.Context, cfg Config) (ReentrantLocker, error) {
+	l, err := New(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	rl, ok := l.(ReentrantLocker)
+	if !ok {
+		return nil, UnsupportedError{
+			Provider: cfg.Provider,
+			Feature:  "reentrant locks",
+		}
+	}
+
+	return rl, nil
+}
@@ -0,0 +1,36 @@
+package locker
+
+import "context"
+
+// RWLocker acquires locks that are either held by any number of readers or by a single writer.
+// waiting writers do not block new readers, so writers can starve under constant read load.
+// read/write locks are independent from the ones acquired with Locker.Lock, even with the same name.
+type RWLocker interface {
+	RLock(ctx context.Context, name string, opts ...LockOption) (Lock, error)
+	Lock(ctx context.Context, name string, opts ...LockOption) (Lock, error)
+}
+
+// RWProvider is implemented by lockers that support read/write locks.
+type RWProvider interface {
+	RW() RWLocker
+}
+
+// NewRW creates a read/write locker from the provider in cfg, if the provider supports it.
+//
+//nolint:ireturn // depends on provider.
+func NewRW(ctx context.Context, cfg Config) (RWLocker, error) {
+	l, err := New(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	p, ok := l.(RWProvider)
+	if !ok {
+		return nil, UnsupportedError{
+			Provider: cfg.Provider,
+			Feature:  "read/write locks",
+		}
+	}
+
+	return p.RW(), nil
+}
@@ -0,0 +1,39 @@
+package locker
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrNoPermits = errors.New("no permits available")
+
+// Semaphore limits the number of concurrent holders of a name.
+type Semaphore interface {
+	// Acquire takes one of limit permits for name, failing with ErrNoPermits if all of them are taken.
+	// all holders of the same name are expected to use the same limit.
+	Acquire(ctx context.Context, name string, limit int) (Permit, error)
+}
+
+type Permit interface {
+	Release(ctx context.Context) error
+}
+
+// NewSemaphore creates a semaphore from the provider in cfg, if the provider supports it.
+//
+//nolint:ireturn // depends on provider.
+func NewSemaphore(ctx context.Context, cfg Config) (Semaphore, error) {
+	l, err := New(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	s, ok := l.(Semaphore)
+	if !ok {
+		return nil, UnsupportedError{
+			Provider: cfg.Provider,
+			Feature:  "semaphore",
+		}
+	}
+
+	return s, nil
+}
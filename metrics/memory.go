@@ -0,0 +1,219 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog"
+	"github.com/sovamorco/errorx"
+)
+
+//nolint:gochecknoglobals // replacer is stateless.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// Memory keeps metrics in memory and exposes them in prometheus text format.
+type Memory struct {
+	buckets    []float64
+	counters   map[string]map[string]*counter
+	histograms map[string]map[string]*histogram
+	mu         sync.Mutex `exhaustruct:"optional"`
+}
+
+type counter struct {
+	labels Labels
+	value  float64
+}
+
+type histogram struct {
+	labels Labels
+	// counts[i] is the number of observations in (buckets[i-1], buckets[i]], the last one is +Inf.
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+type HistogramSnapshot struct {
+	Count uint64
+	Sum   float64
+}
+
+// NewMemory creates in-memory metrics with histogram buckets, DefaultBuckets if nil.
+func NewMemory(buckets []float64) *Memory {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+
+	buckets = slices.Clone(buckets)
+	slices.Sort(buckets)
+
+	return &Memory{
+		buckets:    buckets,
+		counters:   make(map[string]map[string]*counter),
+		histograms: make(map[string]map[string]*histogram),
+	}
+}
+
+func (m *Memory) Inc(name string, labels Labels) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	series, ok := m.counters[name]
+	if !ok {
+		series = make(map[string]*counter)
+		m.counters[name] = series
+	}
+
+	key := formatLabels(labels, "")
+
+	c, ok := series[key]
+	if !ok {
+		c = &counter{
+			labels: maps.Clone(labels),
+			value:  0,
+		}
+		series[key] = c
+	}
+
+	c.value++
+}
+
+func (m *Memory) Observe(name string, labels Labels, v float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	series, ok := m.histograms[name]
+	if !ok {
+		series = make(map[string]*histogram)
+		m.histograms[name] = series
+	}
+
+	key := formatLabels(labels, "")
+
+	h, ok := series[key]
+	if !ok {
+		h = &histogram{
+			labels: maps.Clone(labels),
+			counts: make([]uint64, len(m.buckets)+1),
+			sum:    0,
+			count:  0,
+		}
+		series[key] = h
+	}
+
+	i, _ := slices.BinarySearch(m.buckets, v)
+	h.counts[i]++
+	h.sum += v
+	h.count++
+}
+
+// Counter returns the value of counter name with labels, useful in tests.
+func (m *Memory) Counter(name string, labels Labels) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, ok := m.counters[name][formatLabels(labels, "")]
+	if !ok {
+		return 0
+	}
+
+	return c.value
+}
+
+// Histogram returns the count and sum of histogram name with labels, useful in tests.
+func (m *Memory) Histogram(name string, labels Labels) HistogramSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	h, ok := m.histograms[name][formatLabels(labels, "")]
+	if !ok {
+		return HistogramSnapshot{Count: 0, Sum: 0}
+	}
+
+	return HistogramSnapshot{
+		Count: h.count,
+		Sum:   h.sum,
+	}
+}
+
+// WritePrometheus writes all metrics in prometheus text exposition format.
+func (m *Memory) WritePrometheus(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+
+	for _, name := range slices.Sorted(maps.Keys(m.counters)) {
+		fmt.Fprintf(bw, "# TYPE %s counter\n", name)
+
+		series := m.counters[name]
+		for _, key := range slices.Sorted(maps.Keys(series)) {
+			fmt.Fprintf(bw, "%s%s %s\n", name, key, formatFloat(series[key].value))
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(m.histograms)) {
+		fmt.Fprintf(bw, "# TYPE %s histogram\n", name)
+
+		series := m.histograms[name]
+		for _, key := range slices.Sorted(maps.Keys(series)) {
+			m.writeHistogram(bw, name, series[key])
+		}
+	}
+
+	return errorx.Wrap(bw.Flush(), "flush metrics")
+}
+
+func (m *Memory) writeHistogram(w io.Writer, name string, h *histogram) {
+	var cumulative uint64
+
+	for i, le := range m.buckets {
+		cumulative += h.counts[i]
+
+		fmt.Fprintf(w, "%s_bucket%s %d\n", name, formatLabels(h.labels, formatFloat(le)), cumulative)
+	}
+
+	fmt.Fprintf(w, "%s_bucket%s %d\n", name, formatLabels(h.labels, "+Inf"), h.count)
+
+	key := formatLabels(h.labels, "")
+	fmt.Fprintf(w, "%s_sum%s %s\n", name, key, formatFloat(h.sum))
+	fmt.Fprintf(w, "%s_count%s %d\n", name, key, h.count)
+}
+
+func (m *Memory) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	err := m.WritePrometheus(w)
+	if err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("Failed to write metrics")
+	}
+}
+
+// formatLabels returns labels in exposition format sorted by name, with le label appended if not empty.
+func formatLabels(labels Labels, le string) string {
+	if len(labels) == 0 && le == "" {
+		return ""
+	}
+
+	parts := make([]string, 0, len(labels)+1)
+
+	for _, k := range slices.Sorted(maps.Keys(labels)) {
+		parts = append(parts, k+`="`+labelEscaper.Replace(labels[k])+`"`)
+	}
+
+	if le != "" {
+		parts = append(parts, `le="`+le+`"`)
+	}
+
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,42 @@
+package metrics_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sovamorco/gommon/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	t.Parallel()
+
+	m := metrics.NewMemory([]float64{0.1, 1})
+
+	m.Inc("hits_total", metrics.Labels{"namespace": "users"})
+	m.Inc("hits_total", metrics.Labels{"namespace": "users"})
+	m.Inc("hits_total", metrics.Labels{"namespace": `"quoted"`})
+	m.Observe("duration_seconds", nil, 0.1)
+	m.Observe("duration_seconds", nil, 0.5)
+	m.Observe("duration_seconds", nil, 5)
+
+	var sb strings.Builder
+
+	require.NoError(t, m.WritePrometheus(&sb))
+
+	expected := `# TYPE hits_total counter
+hits_total{namespace="\"quoted\""} 1
+hits_total{namespace="users"} 2
+# TYPE duration_seconds histogram
+duration_seconds_bucket{le="0.1"} 1
+duration_seconds_bucket{le="1"} 2
+duration_seconds_bucket{le="+Inf"} 3
+duration_seconds_sum 5.6
+duration_seconds_count 3
+`
+
+	assert.Equal(t, expected, sb.String())
+	assert.InDelta(t, 2.0, m.Counter("hits_total", metrics.Labels{"namespace": "users"}), 0)
+	assert.Equal(t, uint64(3), m.Histogram("duration_seconds", nil).Count)
+}
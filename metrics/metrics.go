@@ -0,0 +1,22 @@
+package metrics
+
+// DefaultBuckets are histogram buckets in seconds, same as default prometheus buckets.
+//
+//nolint:gochecknoglobals,mnd // constant slice.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+type Labels map[string]string
+
+type Metrics interface {
+	// Inc increments counter name with labels by one.
+	Inc(name string, labels Labels)
+	// Observe records v in histogram name with labels.
+	Observe(name string, labels Labels, v float64)
+}
+
+// Nop discards all metrics.
+type Nop struct{}
+
+func (Nop) Inc(string, Labels) {}
+
+func (Nop) Observe(string, Labels, float64) {}
@@ -0,0 +1,227 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/sovamorco/errorx"
+	"github.com/sovamorco/gommon/cache"
+)
+
+const keyPrefix = "ratelimit:"
+
+type Result struct {
+	Allowed bool
+	// quota left after this call.
+	Remaining int64
+	// time until the call may be allowed, zero if it was allowed.
+	RetryAfter time.Duration
+}
+
+type Limiter interface {
+	// Allow takes n units of quota for key if there is enough of it.
+	Allow(ctx context.Context, key string, n int64) (Result, error)
+}
+
+// FixedWindow allows limit units per window, windows are aligned to the unix epoch.
+type FixedWindow struct {
+	c      cache.Cache
+	limit  int64
+	window time.Duration
+}
+
+func NewFixedWindow(c cache.Cache, limit int64, window time.Duration) (*FixedWindow, error) {
+	err := validateWindow(limit, window)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FixedWindow{
+		c:      c,
+		limit:  limit,
+		window: window,
+	}, nil
+}
+
+func (l *FixedWindow) Allow(ctx context.Context, key string, n int64) (Result, error) {
+	now := time.Now()
+	idx, elapsed := windowPosition(now, l.window)
+
+	count, err := take(ctx, l.c, windowKey(key, idx), n, l.limit, l.window)
+	if err != nil {
+		return Result{}, err
+	}
+
+	if count > l.limit {
+		return Result{
+			Allowed:    false,
+			Remaining:  max(0, l.limit-count+n),
+			RetryAfter: l.window - elapsed,
+		}, nil
+	}
+
+	return Result{
+		Allowed:    true,
+		Remaining:  l.limit - count,
+		RetryAfter: 0,
+	}, nil
+}
+
+// SlidingWindow allows limit units per any window-long period.
+// usage of the previous window is weighted by how much of it still overlaps the period,
+// which approximates a sliding log without storing every call.
+type SlidingWindow struct {
+	c      cache.Cache
+	limit  int64
+	window time.Duration
+}
+
+func NewSlidingWindow(c cache.Cache, limit int64, window time.Duration) (*SlidingWindow, error) {
+	err := validateWindow(limit, window)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SlidingWindow{
+		c:      c,
+		limit:  limit,
+		window: window,
+	}, nil
+}
+
+func (l *SlidingWindow) Allow(ctx context.Context, key string, n int64) (Result, error) {
+	now := time.Now()
+	idx, elapsed := windowPosition(now, l.window)
+
+	prev, err := getCount(ctx, l.c, windowKey(key, idx-1))
+	if err != nil {
+		return Result{}, err
+	}
+
+	weight := 1 - float64(elapsed)/float64(l.window)
+	prevWeighted := int64(float64(prev) * weight)
+
+	// current window counter has to outlive the next window, where it is used as previous.
+	count, err := take(ctx, l.c, windowKey(key, idx), n, l.limit-prevWeighted, 2*l.window)
+	if err != nil {
+		return Result{}, err
+	}
+
+	used := prevWeighted + count
+	if used <= l.limit {
+		return Result{
+			Allowed:    true,
+			Remaining:  l.limit - used,
+			RetryAfter: 0,
+		}, nil
+	}
+
+	return Result{
+		Allowed:    false,
+		Remaining:  max(0, l.limit-used+n),
+		RetryAfter: l.retryAfter(prev, count, elapsed),
+	}, nil
+}
+
+// retryAfter returns the time until the weight of the previous window drops enough to fit the call,
+// or until the end of the current window if current usage alone does not fit it.
+func (l *SlidingWindow) retryAfter(prev, count int64, elapsed time.Duration) time.Duration {
+	free := l.limit - count
+	if prev == 0 || free < 0 {
+		return l.window - elapsed
+	}
+
+	// prev * (1 - (elapsed + t) / window) <= free.
+	t := time.Duration(float64(l.window)*(1-float64(free)/float64(prev))) - elapsed
+
+	return max(t, time.Millisecond)
+}
+
+// TokenBucket allows bursts of up to burst units, refilled at rate units per second.
+// requires a cache implementing cache.TokenBucket.
+type TokenBucket struct {
+	c     cache.Cache
+	rate  float64
+	burst int64
+}
+
+func NewTokenBucket(c cache.Cache, rate float64, burst int64) (*TokenBucket, error) {
+	if rate <= 0 || burst <= 0 {
+		return nil, errorx.IllegalArgument.New("rate and burst must be positive, got %v and %d", rate, burst)
+	}
+
+	return &TokenBucket{
+		c:     c,
+		rate:  rate,
+		burst: burst,
+	}, nil
+}
+
+func (l *TokenBucket) Allow(ctx context.Context, key string, n int64) (Result, error) {
+	if n > l.burst {
+		return Result{}, errorx.IllegalArgument.New("cannot take %d tokens from bucket of %d", n, l.burst)
+	}
+
+	tokens, err := cache.TakeTokens(ctx, l.c, keyPrefix+key, l.rate, l.burst, n)
+	if err != nil {
+		return Result{}, errorx.Wrap(err, "take tokens")
+	}
+
+	return Result{
+		Allowed:    tokens.Taken,
+		Remaining:  tokens.Remaining,
+		RetryAfter: tokens.RetryAfter,
+	}, nil
+}
+
+// take increments the counter and rolls the increment back if it goes over limit,
+// so that rejected calls do not use up quota. returns the counter value including n.
+func take(ctx context.Context, c cache.Cache, key string, n, limit int64, lifetime time.Duration) (int64, error) {
+	count, err := c.IncrBy(ctx, key, n, lifetime)
+	if err != nil {
+		return 0, errorx.Wrap(err, "increment counter")
+	}
+
+	if count > limit {
+		_, err = c.IncrBy(ctx, key, -n, lifetime)
+		if err != nil {
+			return 0, errorx.Wrap(err, "roll back counter")
+		}
+	}
+
+	return count, nil
+}
+
+func getCount(ctx context.Context, c cache.Cache, key string) (int64, error) {
+	v, err := c.Get(ctx, key)
+	if errors.Is(err, cache.ErrNotExist) {
+		return 0, nil
+	} else if err != nil {
+		return 0, errorx.Wrap(err, "get counter")
+	}
+
+	res, err := strconv.ParseInt(string(v), 10, 64)
+
+	return res, errorx.Wrap(err, "parse counter")
+}
+
+func validateWindow(limit int64, window time.Duration) error {
+	if limit <= 0 || window <= 0 {
+		return errorx.IllegalArgument.New("limit and window must be positive, got %d and %s", limit, window)
+	}
+
+	return nil
+}
+
+func windowPosition(now time.Time, window time.Duration) (int64, time.Duration) {
+	ns := now.UnixNano()
+
+	return ns / int64(window), time.Duration(ns % int64(window))
+}
+
+func windowKey(key string, idx int64) string {
+	return fmt.Sprintf("%s%s:%d", keyPrefix, key, idx)
+}
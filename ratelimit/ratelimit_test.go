@@ -0,0 +1,105 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sovamorco/gommon/cache"
+	_ "github.com/sovamorco/gommon/cache/mock"
+	"github.com/sovamorco/gommon/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiters(t *testing.T) {
+	t.Parallel()
+
+	c, err := cache.New(context.Background(), cache.Config{Provider: "mock", URL: "", Compression: nil, Encryption: nil, Local: nil})
+	require.NoError(t, err)
+
+	fw, err := ratelimit.NewFixedWindow(c, 3, time.Hour)
+	require.NoError(t, err)
+
+	sw, err := ratelimit.NewSlidingWindow(c, 3, time.Hour)
+	require.NoError(t, err)
+
+	tb, err := ratelimit.NewTokenBucket(c, 0.001, 3)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name string
+		l    ratelimit.Limiter
+	}{
+		{
+			name: "Fixed window",
+			l:    fw,
+		},
+		{
+			name: "Sliding window",
+			l:    sw,
+		},
+		{
+			name: "Token bucket",
+			l:    tb,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			testLimiter(t, tc.l, tc.name)
+		})
+	}
+}
+
+func TestValidation(t *testing.T) {
+	t.Parallel()
+
+	c, err := cache.New(context.Background(), cache.Config{Provider: "mock", URL: "", Compression: nil, Encryption: nil, Local: nil})
+	require.NoError(t, err)
+
+	_, err = ratelimit.NewFixedWindow(c, 3, 0)
+	require.Error(t, err)
+
+	_, err = ratelimit.NewFixedWindow(c, 0, time.Hour)
+	require.Error(t, err)
+
+	_, err = ratelimit.NewSlidingWindow(c, 3, 0)
+	require.Error(t, err)
+
+	_, err = ratelimit.NewSlidingWindow(c, -1, time.Hour)
+	require.Error(t, err)
+
+	_, err = ratelimit.NewTokenBucket(c, 0, 3)
+	require.Error(t, err)
+
+	_, err = ratelimit.NewTokenBucket(c, 1, 0)
+	require.Error(t, err)
+
+	_, err = cache.TakeTokens(context.Background(), c, "key", 0, 3, 1)
+	require.Error(t, err)
+}
+
+func testLimiter(t *testing.T, l ratelimit.Limiter, key string) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	res, err := l.Allow(ctx, key, 2)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, int64(1), res.Remaining)
+
+	res, err = l.Allow(ctx, key, 2)
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, int64(1), res.Remaining)
+	assert.Positive(t, res.RetryAfter)
+
+	res, err = l.Allow(ctx, key, 1)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, int64(0), res.Remaining)
+}
@@ -0,0 +1,253 @@
+package scheduler
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sovamorco/errorx"
+	"github.com/sovamorco/gommon/config"
+)
+
+// how far Next looks for a matching time before giving up, covers leap days.
+const maxLookahead = 5 * 366 * 24 * time.Hour
+
+// Schedule returns start of the first run slot after t, zero if there is none.
+// all replicas have to compute the same slots for runs to be deduplicated.
+type Schedule interface {
+	Next(t time.Time) time.Time
+}
+
+// Every runs at multiples of d since zero time, so that slots are the same on every replica.
+type Every time.Duration
+
+func (e Every) Next(t time.Time) time.Time {
+	d := time.Duration(e)
+
+	return t.Truncate(d).Add(d)
+}
+
+// Parse accepts cron expressions with 5 fields, descriptors such as @daily or @every 5m,
+// and durations in the same format as duration values in config.
+//
+//nolint:ireturn // depends on spec.
+func Parse(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+
+	if every, ok := strings.CutPrefix(spec, "@every "); ok {
+		return parseEvery(strings.TrimSpace(every))
+	}
+
+	if cron, ok := descriptors[spec]; ok {
+		spec = cron
+	}
+
+	if len(strings.Fields(spec)) == 1 {
+		return parseEvery(spec)
+	}
+
+	c, err := ParseCron(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+//nolint:ireturn // Every or Cron.
+func parseEvery(spec string) (Schedule, error) {
+	v, err := config.DurationInterpolator(spec)
+	if err != nil {
+		return nil, errorx.Wrap(err, "parse duration")
+	}
+
+	d, _ := v.(time.Duration)
+	if d <= 0 {
+		return nil, errorx.IllegalArgument.New("interval must be positive, got %s", d)
+	}
+
+	return Every(d), nil
+}
+
+//nolint:gochecknoglobals // constant lookup tables.
+var (
+	descriptors = map[string]string{
+		"@yearly":   "0 0 1 1 *",
+		"@annually": "0 0 1 1 *",
+		"@monthly":  "0 0 1 * *",
+		"@weekly":   "0 0 * * 0",
+		"@daily":    "0 0 * * *",
+		"@midnight": "0 0 * * *",
+		"@hourly":   "0 * * * *",
+	}
+	monthNames = []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+	dayNames   = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+)
+
+// Cron is a parsed cron expression, evaluated in the location of the time passed to Next.
+type Cron struct {
+	minute, hour, dom, month, dow uint64
+	// when both day fields are restricted, either of them has to match like in standard cron.
+	domStar, dowStar bool
+}
+
+// ParseCron parses standard cron expression with minute, hour, day of month, month and day of week fields.
+// fields support *, lists, ranges and steps, months and days of week can be given as names.
+func ParseCron(expr string) (*Cron, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 { //nolint:mnd // number of cron fields.
+		return nil, errorx.IllegalFormat.New("cron expression must have 5 fields, got %d", len(fields))
+	}
+
+	specs := []struct {
+		name     string
+		min, max int
+		names    []string
+	}{
+		{"minute", 0, 59, nil},
+		{"hour", 0, 23, nil},
+		{"day of month", 1, 31, nil},
+		{"month", 1, 12, monthNames},
+		{"day of week", 0, 7, dayNames},
+	}
+
+	bits := make([]uint64, len(fields))
+
+	for i, f := range fields {
+		s := specs[i]
+
+		b, err := parseField(f, s.min, s.max, s.names)
+		if err != nil {
+			return nil, errorx.Wrap(err, "parse %s", s.name)
+		}
+
+		bits[i] = b
+	}
+
+	// 7 is sunday as well.
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+
+	return &Cron{
+		minute:  bits[0],
+		hour:    bits[1],
+		dom:     bits[2],
+		month:   bits[3],
+		dow:     bits[4],
+		domStar: strings.HasPrefix(fields[2], "*"),
+		dowStar: strings.HasPrefix(fields[4], "*"),
+	}, nil
+}
+
+func parseField(field string, lo, hi int, names []string) (uint64, error) {
+	var res uint64
+
+	for part := range strings.SplitSeq(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+
+		step := 1
+
+		if hasStep {
+			var err error
+
+			step, err = strconv.Atoi(stepStr)
+			if err != nil || step <= 0 {
+				return 0, errorx.IllegalFormat.New("invalid step %q", stepStr)
+			}
+		}
+
+		start, end := lo, hi
+
+		if rng != "*" {
+			startStr, endStr, isRange := strings.Cut(rng, "-")
+
+			var err error
+
+			start, err = parseValue(startStr, lo, hi, names)
+			if err != nil {
+				return 0, err
+			}
+
+			end = start
+
+			switch {
+			case isRange:
+				end, err = parseValue(endStr, lo, hi, names)
+				if err != nil {
+					return 0, err
+				}
+			case hasStep:
+				end = hi
+			}
+
+			if end < start {
+				return 0, errorx.IllegalFormat.New("invalid range %q", rng)
+			}
+		}
+
+		for v := start; v <= end; v += step {
+			res |= 1 << v
+		}
+	}
+
+	return res, nil
+}
+
+func parseValue(s string, lo, hi int, names []string) (int, error) {
+	for i, name := range names {
+		if strings.EqualFold(s, name) {
+			// month names start from 1, day names from 0.
+			return i + lo, nil
+		}
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, errorx.IllegalFormat.New("invalid value %q", s)
+	}
+
+	if v < lo || v > hi {
+		return 0, errorx.IllegalFormat.New("value %d out of range %d-%d", v, lo, hi)
+	}
+
+	return v, nil
+}
+
+func (c *Cron) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxLookahead)
+
+	for t.Before(limit) {
+		switch {
+		case !has(c.month, int(t.Month())):
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case !has(c.hour, t.Hour()):
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		case !has(c.minute, t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+func (c *Cron) dayMatches(t time.Time) bool {
+	dom := has(c.dom, t.Day())
+	dow := has(c.dow, int(t.Weekday()))
+
+	if c.domStar || c.dowStar {
+		return dom && dow
+	}
+
+	return dom || dow
+}
+
+func has(bits uint64, v int) bool {
+	return bits&(1<<v) != 0
+}
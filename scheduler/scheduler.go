@@ -0,0 +1,270 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/sovamorco/errorx"
+	"github.com/sovamorco/gommon/cache"
+	"github.com/sovamorco/gommon/locker"
+)
+
+const keyPrefix = "scheduler"
+
+type Job func(ctx context.Context) error
+
+// Scheduler runs jobs once per run slot across all replicas sharing the locker and cache.
+// each job is locked while it runs, and the last run slot is kept in cache and only moved forward,
+// so that replicas with lagging clocks do not run the slot again after the lock is released.
+// runs of the same job do not overlap, slots missed while the job was running are skipped.
+type Scheduler struct {
+	locker   locker.Locker
+	cache    cache.Cache
+	location *time.Location
+
+	jobs map[string]job
+
+	loops      sync.WaitGroup `exhaustruct:"optional"`
+	stop       chan struct{}
+	stopOnce   sync.Once `exhaustruct:"optional"`
+	cancelJobs context.CancelFunc
+	mu         sync.Mutex `exhaustruct:"optional"`
+}
+
+type job struct {
+	name     string
+	schedule Schedule
+	f        Job
+}
+
+type Option func(s *Scheduler)
+
+// WithLocation sets location cron expressions are evaluated in, UTC by default.
+func WithLocation(loc *time.Location) Option {
+	return func(s *Scheduler) {
+		s.location = loc
+	}
+}
+
+func New(l locker.Locker, c cache.Cache, opts ...Option) *Scheduler {
+	s := &Scheduler{
+		locker:     l,
+		cache:      c,
+		location:   time.UTC,
+		jobs:       make(map[string]job),
+		stop:       make(chan struct{}),
+		cancelJobs: nil,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Add registers job running on spec, see Parse for supported formats.
+// name identifies the job across replicas, so it has to be unique.
+func (s *Scheduler) Add(name, spec string, f Job) error {
+	sched, err := Parse(spec)
+	if err != nil {
+		return errorx.Wrap(err, "parse schedule of %s", name)
+	}
+
+	return s.AddSchedule(name, sched, f)
+}
+
+func (s *Scheduler) AddSchedule(name string, sched Schedule, f Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.jobs[name]; ok {
+		return errorx.IllegalArgument.New("job %s is already registered", name)
+	}
+
+	s.jobs[name] = job{
+		name:     name,
+		schedule: sched,
+		f:        f,
+	}
+
+	return nil
+}
+
+// Start schedules registered jobs until ctx is done or Shutdown is called.
+// jobs get ctx values, but are only cancelled by Shutdown, jobs added after Start are not scheduled.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancelJobs != nil {
+		return
+	}
+
+	var jobsCtx context.Context
+
+	jobsCtx, s.cancelJobs = context.WithCancel(context.WithoutCancel(ctx))
+
+	for _, j := range s.jobs {
+		s.loops.Add(1)
+
+		go func() {
+			defer s.loops.Done()
+
+			s.loop(ctx, jobsCtx, j)
+		}()
+	}
+}
+
+// Shutdown stops scheduling new runs and waits for running jobs.
+// if ctx is done first, running jobs are cancelled and ctx error is returned.
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+
+	s.mu.Lock()
+	cancel := s.cancelJobs
+	s.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		s.loops.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		cancel()
+
+		return errorx.Wrap(ctx.Err(), "wait for running jobs")
+	}
+}
+
+func (s *Scheduler) loop(ctx, jobsCtx context.Context, j job) {
+	logger := zerolog.Ctx(ctx).With().Str("job", j.name).Logger()
+
+	for {
+		slot := j.schedule.Next(time.Now().In(s.location))
+		if slot.IsZero() {
+			logger.Warn().Msg("Job has no more runs scheduled")
+
+			return
+		}
+
+		t := time.NewTimer(time.Until(slot))
+
+		select {
+		case <-ctx.Done():
+			t.Stop()
+
+			return
+		case <-s.stop:
+			t.Stop()
+
+			return
+		case <-t.C:
+		}
+
+		err := s.run(jobsCtx, j, slot)
+		if err != nil {
+			logger.Error().Err(err).Time("slot", slot).Msg("Failed to run job")
+		}
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, j job, slot time.Time) error {
+	// lock name does not depend on the slot, so that locking does not leave state behind for every run.
+	err := locker.WithLock(ctx, s.locker, keyPrefix+":"+j.name, func(ctx context.Context) error {
+		claimed, err := s.claim(ctx, j.name, slot)
+		if err != nil || !claimed {
+			return err
+		}
+
+		return j.f(ctx)
+	})
+	// job is run by another replica.
+	if errors.Is(err, locker.ErrLocked) {
+		return nil
+	}
+
+	return err
+}
+
+// claim moves the last run of job name forward to slot, reporting false if slot or a later one was run already.
+func (s *Scheduler) claim(ctx context.Context, name string, slot time.Time) (bool, error) {
+	key := lastRunKey(name)
+	value := []byte(strconv.FormatInt(slot.UnixNano(), 10))
+
+	for {
+		v, version, err := s.cache.GetVersioned(ctx, key)
+		if errors.Is(err, cache.ErrNotExist) {
+			set, err := s.cache.SetIfNotExists(ctx, key, value, 0)
+			if err != nil {
+				return false, errorx.Wrap(err, "record last run")
+			}
+
+			if set {
+				return true, nil
+			}
+
+			continue
+		} else if err != nil {
+			return false, errorx.Wrap(err, "get last run")
+		}
+
+		last, err := strconv.ParseInt(string(v), 10, 64)
+		if err != nil {
+			return false, errorx.Wrap(err, "parse last run")
+		}
+
+		if last >= slot.UnixNano() {
+			return false, nil
+		}
+
+		swapped, err := s.cache.CompareAndSwap(ctx, key, version, value, 0)
+		if errors.Is(err, cache.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return false, errorx.Wrap(err, "record last run")
+		}
+
+		if swapped {
+			return true, nil
+		}
+	}
+}
+
+// LastRun returns start of the last slot job name was run for, zero if it was never run.
+func (s *Scheduler) LastRun(ctx context.Context, name string) (time.Time, error) {
+	v, err := s.cache.Get(ctx, lastRunKey(name))
+	if errors.Is(err, cache.ErrNotExist) {
+		return time.Time{}, nil
+	}
+
+	if err != nil {
+		return time.Time{}, errorx.Wrap(err, "get last run")
+	}
+
+	last, err := strconv.ParseInt(string(v), 10, 64)
+	if err != nil {
+		return time.Time{}, errorx.Wrap(err, "parse last run")
+	}
+
+	return time.Unix(0, last).In(s.location), nil
+}
+
+func lastRunKey(name string) string {
+	return keyPrefix + ":" + name + ":last_run"
+}
@@ -0,0 +1,166 @@
+package scheduler_test
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sovamorco/gommon/cache"
+	_ "github.com/sovamorco/gommon/cache/mock"
+	"github.com/sovamorco/gommon/locker/memory"
+	"github.com/sovamorco/gommon/scheduler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	base := time.Date(2024, time.January, 31, 10, 30, 15, 0, time.UTC) // wednesday.
+
+	cases := []struct {
+		spec     string
+		expected time.Time
+	}{
+		{"*/15 * * * *", time.Date(2024, time.January, 31, 10, 45, 0, 0, time.UTC)},
+		{"0 9-17/4 * * mon-fri", time.Date(2024, time.January, 31, 13, 0, 0, 0, time.UTC)},
+		{"0 0 29 feb *", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * 7", time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"@every 1h", time.Date(2024, time.January, 31, 11, 0, 0, 0, time.UTC)},
+		{"10m", time.Date(2024, time.January, 31, 10, 40, 0, 0, time.UTC)},
+	}
+
+	for _, tc := range cases {
+		sched, err := scheduler.Parse(tc.spec)
+		require.NoError(t, err, tc.spec)
+		assert.Equal(t, tc.expected, sched.Next(base), tc.spec)
+	}
+
+	for _, spec := range []string{"* * * *", "60 * * * *", "* * * foo *", "5-1 * * * *", "-1m"} {
+		_, err := scheduler.Parse(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	c, err := cache.New(ctx, cache.Config{Provider: "mock", URL: "", Compression: nil, Encryption: nil, Local: nil})
+	require.NoError(t, err)
+
+	l := memory.New(0)
+
+	const interval = 100 * time.Millisecond
+
+	var (
+		mu   sync.Mutex
+		runs = make(map[time.Time]int)
+	)
+
+	schedulers := make([]*scheduler.Scheduler, 2)
+
+	for i := range schedulers {
+		s := scheduler.New(l, c)
+
+		require.NoError(t, s.AddSchedule("job", scheduler.Every(interval), func(context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+
+			runs[time.Now().Truncate(interval)]++
+
+			return nil
+		}))
+
+		s.Start(ctx)
+
+		schedulers[i] = s
+	}
+
+	time.Sleep(5*interval + interval/2)
+
+	for _, s := range schedulers {
+		require.NoError(t, s.Shutdown(ctx))
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.GreaterOrEqual(t, len(runs), 4)
+
+	for slot, n := range runs {
+		assert.Equal(t, 1, n, slot)
+	}
+
+	last, err := schedulers[0].LastRun(ctx, "job")
+	require.NoError(t, err)
+	assert.False(t, last.IsZero())
+}
+
+func TestShutdown(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	c, err := cache.New(ctx, cache.Config{Provider: "mock", URL: "", Compression: nil, Encryption: nil, Local: nil})
+	require.NoError(t, err)
+
+	s := scheduler.New(memory.New(0), c)
+
+	started := make(chan struct{})
+
+	require.NoError(t, s.Add("job", "@every 10ms", func(ctx context.Context) error {
+		close(started)
+
+		<-ctx.Done()
+
+		return ctx.Err()
+	}))
+
+	s.Start(ctx)
+
+	<-started
+
+	sctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+
+	require.ErrorIs(t, s.Shutdown(sctx), context.DeadlineExceeded)
+}
+
+func TestLastRunOnlyMovesForward(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	c, err := cache.New(ctx, cache.Config{Provider: "mock", URL: "", Compression: nil, Encryption: nil, Local: nil})
+	require.NoError(t, err)
+
+	// another replica with a clock ahead has run a later slot already.
+	future := time.Now().Add(time.Hour).Truncate(time.Second)
+	require.NoError(t, c.Set(ctx, "scheduler:job:last_run", []byte(strconv.FormatInt(future.UnixNano(), 10)), 0))
+
+	s := scheduler.New(memory.New(0), c)
+
+	var runs atomic.Int32
+
+	require.NoError(t, s.Add("job", "@every 10ms", func(context.Context) error {
+		runs.Add(1)
+
+		return nil
+	}))
+
+	s.Start(ctx)
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, s.Shutdown(ctx))
+
+	assert.Zero(t, runs.Load())
+
+	last, err := s.LastRun(ctx, "job")
+	require.NoError(t, err)
+	assert.True(t, future.Equal(last))
+}
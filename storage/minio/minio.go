@@ -108,3 +108,17 @@ func (s *Storage) GetLink(ctx context.Context, path string) (string, error) {
 
 	return u.String(), nil
 }
+
+// Ping checks that the bucket is reachable.
+func (s *Storage) Ping(ctx context.Context) error {
+	exists, err := s.client.BucketExists(ctx, s.bucketName)
+	if err != nil {
+		return errorx.Wrap(err, "check bucket existence")
+	}
+
+	if !exists {
+		return errorx.IllegalState.New("bucket %s does not exist", s.bucketName)
+	}
+
+	return nil
+}
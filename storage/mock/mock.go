@@ -110,3 +110,7 @@ func (s *Storage) Download(ctx context.Context, path string) (*storage.Object, e
 func (s *Storage) GetLink(_ context.Context, path string) (string, error) {
 	return fmt.Sprintf("http://127.0.0.1:%d/%s", s.port, path), nil
 }
+
+func (s *Storage) Ping(_ context.Context) error {
+	return nil
+}